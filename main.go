@@ -1,84 +1,88 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
 )
 
-var printMap = func(m map[string]string) {
-	for a, b := range m {
-		fmt.Printf("%s: %s\n", a, b)
-	}
+var printStruct = func(v interface{}) {
+	fmt.Printf("%+v\n", v)
 	fmt.Println("")
 }
 
 func main() {
-	s := softether.SoftEther{IP: "54.89.114.55", Password: "subspace", Hub: "subspace"}
+	s := softether.SoftEther{IP: "54.89.114.55", Password: "subspace", Hub: "subspace", Timeout: 30 * time.Second}
+	ctx := context.Background()
 
 	// Get Server Status
-	serverStatus, _ := s.GetServerStatus()
+	serverStatus, _ := s.GetServerStatus(ctx)
 	fmt.Println("Server Status")
 	fmt.Println("-------------")
-	printMap(serverStatus)
+	printStruct(serverStatus)
 
 	// Set PreShared Key
-	err := s.SetPreSharedKey("abcdefg")
-	fmt.Println(err)
-	fmt.Println("ERROR: ", softether.Strerror(err))
+	err := s.SetPreSharedKey(ctx, "abcdefg")
+	fmt.Println("ERROR: ", err)
 
 	// Get Session List
-	sessionList, _ := s.GetSessionList()
+	sessionList, _ := s.GetSessionList(ctx)
 	fmt.Println("Session List")
 	fmt.Println("-------------")
 	for a, session := range sessionList {
 		fmt.Printf("[Session %d]\n", a)
-		for c, d := range session {
-			fmt.Printf("%s: %s\n", c, d)
-		}
-		fmt.Println("")
+		printStruct(session)
 	}
 	fmt.Println("")
 
 	// Get Session Info
 	fmt.Println("Session Info")
 	fmt.Println("-------------")
-	sessionInfo, _ := s.GetSessionInfo("SID-SECURENAT-1")
-	printMap(sessionInfo)
+	sessionInfo, _ := s.GetSessionInfo(ctx, "SID-SECURENAT-1")
+	printStruct(sessionInfo)
+
+	// Get SecureNAT Status
+	fmt.Println("SecureNAT Status")
+	fmt.Println("----------------")
+	secureNATStatus, _ := s.GetSecureNATStatus(ctx)
+	printStruct(secureNATStatus)
 
 	// Create User, Set Password and Get User Info
-	s.CreateUser("1", "test@ecoworkinc.com", "New Account")
-	s.SetUserPassword("1", "abcde")
-	createdUser, _ := s.GetUserInfo("1")
+	s.CreateUser(ctx, "1", "test@ecoworkinc.com", "New Account")
+	s.SetUserPassword(ctx, "1", "abcde")
+	createdUser, _ := s.GetUserInfo(ctx, "1")
 	fmt.Println("Created User")
 	fmt.Println("------------")
-	printMap(createdUser)
+	printStruct(createdUser)
 
 	// Update User information
-	s.SetUserInfo("1", "modifiedtest@ecoworkinc.com", "Modified Account Name")
-	updatedUser, _ := s.GetUserInfo("1")
+	s.SetUserInfo(ctx, "1", "modifiedtest@ecoworkinc.com", "Modified Account Name")
+	updatedUser, _ := s.GetUserInfo(ctx, "1")
 	fmt.Println("Updated User Alias")
 	fmt.Println("------------------")
-	printMap(updatedUser)
+	printStruct(updatedUser)
 
 	// Revoke user
-	s.SetUserEnabled("1", false)
-	revokedUser, _ := s.GetUserInfo("1")
+	s.SetUserEnabled(ctx, "1", false)
+	revokedUser, _ := s.GetUserInfo(ctx, "1")
 	fmt.Println("Revoked User")
 	fmt.Println("------------------")
-	printMap(revokedUser)
+	printStruct(revokedUser)
 
 	// Reenable user
-	s.SetUserEnabled("1", true)
-	enabledUser, _ := s.GetUserInfo("1")
+	s.SetUserEnabled(ctx, "1", true)
+	enabledUser, _ := s.GetUserInfo(ctx, "1")
 	fmt.Println("Revoked User")
 	fmt.Println("------------------")
-	printMap(enabledUser)
+	printStruct(enabledUser)
 
 	// Delete the user
-	s.DeleteUser("1")
-	_, err := s.GetUserInfo("1")
+	s.DeleteUser(ctx, "1")
+	_, err = s.GetUserInfo(ctx, "1")
 	fmt.Println("User Deleted")
 	fmt.Println("------------")
-	fmt.Println("Return Code: ", softether.Strerror(err))
+	fmt.Println("Deleted: ", errors.Is(err, softether.ErrObjectNotFound))
 }
@@ -0,0 +1,292 @@
+package softether
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// AccessAction is what an AccessRule does with the packets it matches.
+type AccessAction string
+
+// Access list actions.
+const (
+	AccessPass    AccessAction = "pass"
+	AccessDiscard AccessAction = "discard"
+)
+
+// TCPState restricts an AccessRule to TCP packets of established or unestablished connections.
+type TCPState string
+
+// TCP connection states. TCPAny matches packets regardless of the connection state.
+const (
+	TCPAny           TCPState = ""
+	TCPEstablished   TCPState = "established"
+	TCPUnestablished TCPState = "unestablished"
+)
+
+// AccessRule is an entry of the access list (packet filter) of a Virtual Hub.
+// Empty addresses, user names and ports match any value. Masks may be given as
+// a subnet mask or a prefix length; an address without a mask matches that host only.
+// Delay, Jitter and Loss simulate a bad network for passed packets.
+type AccessRule struct {
+	ID            int // Assigned by the server; ignored by AddAccessRule
+	Action        AccessAction
+	Enabled       bool   // Only filled in by ListAccessRules; rules are added enabled
+	Priority      int    // Rules with a lower value are applied first
+	Description   string // Memo of the rule
+	IPv6          bool
+	SrcIP         string
+	SrcMask       string
+	DestIP        string
+	DestMask      string
+	Protocol      string // tcp, udp, icmpv4, icmpv6, ip or a protocol number; empty means any
+	SrcPortStart  int
+	SrcPortEnd    int
+	DestPortStart int
+	DestPortEnd   int
+	SrcUsername   string
+	DestUsername  string
+	SrcMAC        string
+	SrcMACMask    string
+	DestMAC       string
+	DestMACMask   string
+	TCPState      TCPState
+	Delay         int // Milliseconds
+	Jitter        int // Percent
+	Loss          int // Percent
+}
+
+// parseAccessRule converts a row of AccessList.
+func parseAccessRule(m map[string]string) AccessRule {
+	rule := AccessRule{
+		ID:          parseInt(m["ID"]),
+		Action:      AccessAction(strings.ToLower(parseString(m["Action"]))),
+		Enabled:     parseBool(m["Status"]),
+		Priority:    parseInt(m["Priority"]),
+		Description: parseString(m["Memo"]),
+	}
+	parseAccessContents(&rule, parseString(m["Contents"]))
+	return rule
+}
+
+// parseAccessContents reads the matching conditions of a rule out of the summary
+// shown in the Contents column of AccessList, such as
+// "(ipv4) SrcIPv4=192.168.0.0/255.255.255.0, Protocol=TCP, DstPort=80, Established".
+func parseAccessContents(rule *AccessRule, contents string) {
+	if strings.HasPrefix(contents, "(ipv6)") {
+		rule.IPv6 = true
+	}
+	if i := strings.Index(contents, ")"); strings.HasPrefix(contents, "(") && i >= 0 {
+		contents = contents[i+1:]
+	}
+
+	for _, item := range strings.Split(contents, ",") {
+		key, value := strings.TrimSpace(item), ""
+		if i := strings.Index(key, "="); i >= 0 {
+			key, value = key[:i], key[i+1:]
+		}
+
+		switch key {
+		case "SrcIPv4", "SrcIPv6":
+			rule.SrcIP, rule.SrcMask = splitMask(value)
+		case "DstIPv4", "DstIPv6":
+			rule.DestIP, rule.DestMask = splitMask(value)
+		case "Protocol":
+			rule.Protocol = strings.ToLower(value)
+		case "SrcPort":
+			rule.SrcPortStart, rule.SrcPortEnd = splitPortRange(value)
+		case "DstPort":
+			rule.DestPortStart, rule.DestPortEnd = splitPortRange(value)
+		case "SrcUser":
+			rule.SrcUsername = value
+		case "DstUser":
+			rule.DestUsername = value
+		case "SrcMac":
+			rule.SrcMAC, rule.SrcMACMask = splitMask(value)
+		case "DstMac":
+			rule.DestMAC, rule.DestMACMask = splitMask(value)
+		case "Established":
+			rule.TCPState = TCPEstablished
+		case "Unestablished":
+			rule.TCPState = TCPUnestablished
+		case "Delay":
+			rule.Delay = parseInt(value)
+		case "Jitter":
+			rule.Jitter = parseInt(value)
+		case "Loss":
+			rule.Loss = parseInt(value)
+		}
+	}
+}
+
+// splitMask splits "address/mask" into its parts.
+func splitMask(value string) (address, mask string) {
+	if i := strings.LastIndex(value, "/"); i >= 0 {
+		return value[:i], value[i+1:]
+	}
+	return value, ""
+}
+
+// splitPortRange splits "start-end" or "port" into a range.
+func splitPortRange(value string) (start, end int) {
+	if i := strings.Index(value, "-"); i >= 0 {
+		return parseInt(value[:i]), parseInt(value[i+1:])
+	}
+	start = parseInt(value)
+	return start, start
+}
+
+// joinMask formats an address for AccessAdd. An empty address matches any
+// address, an address without a mask a single host.
+func joinMask(address, mask string, ipv6 bool) string {
+	if address == "" {
+		if ipv6 {
+			return "::/0"
+		}
+		return "0.0.0.0/0"
+	}
+	if mask == "" {
+		if ipv6 {
+			return address + "/128"
+		}
+		return address + "/32"
+	}
+	return address + "/" + mask
+}
+
+// joinPortRange formats a port range for AccessAdd. Port 0 matches any port.
+func joinPortRange(start, end int) string {
+	if start == 0 {
+		return ""
+	}
+	if end == 0 || end == start {
+		return strconv.Itoa(start)
+	}
+	return strconv.Itoa(start) + "-" + strconv.Itoa(end)
+}
+
+// joinMACMask formats a MAC address for AccessAdd. An empty address matches any address.
+func joinMACMask(address, mask string) string {
+	if address == "" || mask == "" {
+		return address
+	}
+	return address + "/" + mask
+}
+
+// ListAccessRules executes vpncmd and gets the access list of a specific Hub.
+func (s SoftEther) ListAccessRules(ctx context.Context) (ruleList []AccessRule, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd AccessList
+	cmd := s.hubCommand("AccessList")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	rows, err := parseTable(cmd, cmdOutput, "ID")
+	for _, row := range rows {
+		ruleList = append(ruleList, parseAccessRule(row))
+	}
+
+	return
+}
+
+// AddAccessRule executes vpncmd and adds a rule to the access list of a specific Hub.
+// Rules with a Delay, Jitter or Loss are added with AccessAddEx or AccessAddEx6,
+// which only accept pass rules.
+func (s SoftEther) AddAccessRule(ctx context.Context, rule AccessRule) (err error) {
+
+	// Each address family has its own command, the network simulation needs the extended one
+	command := "AccessAdd"
+	extended := rule.Delay != 0 || rule.Jitter != 0 || rule.Loss != 0
+	if extended {
+		command = "AccessAddEx"
+	}
+	if rule.IPv6 {
+		command += "6"
+	}
+
+	protocol := rule.Protocol
+	if protocol == "" {
+		protocol = "ip"
+	}
+
+	args := []string{
+		string(rule.Action),
+		"/MEMO:" + rule.Description,
+		"/PRIORITY:" + strconv.Itoa(rule.Priority),
+		"/SRCUSERNAME:" + rule.SrcUsername,
+		"/DESTUSERNAME:" + rule.DestUsername,
+		"/SRCMAC:" + joinMACMask(rule.SrcMAC, rule.SrcMACMask),
+		"/DESTMAC:" + joinMACMask(rule.DestMAC, rule.DestMACMask),
+		"/SRCIP:" + joinMask(rule.SrcIP, rule.SrcMask, rule.IPv6),
+		"/DESTIP:" + joinMask(rule.DestIP, rule.DestMask, rule.IPv6),
+		"/PROTOCOL:" + protocol,
+		"/SRCPORT:" + joinPortRange(rule.SrcPortStart, rule.SrcPortEnd),
+		"/DESTPORT:" + joinPortRange(rule.DestPortStart, rule.DestPortEnd),
+		"/TCPSTATE:" + string(rule.TCPState),
+	}
+	if extended {
+		args = append(args,
+			"/DELAY:"+strconv.Itoa(rule.Delay),
+			"/JITTER:"+strconv.Itoa(rule.Jitter),
+			"/LOSS:"+strconv.Itoa(rule.Loss),
+		)
+	}
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd AccessAdd|AccessAddEx|AccessAdd6|AccessAddEx6 [pass|discard] /MEMO:[DESCRIPTION] /PRIORITY:[PRIORITY] /SRCUSERNAME:[USERNAME] /DESTUSERNAME:[USERNAME] /SRCMAC:[MAC/MASK] /DESTMAC:[MAC/MASK] /SRCIP:[IP/MASK] /DESTIP:[IP/MASK] /PROTOCOL:[PROTOCOL] /SRCPORT:[START-END] /DESTPORT:[START-END] /TCPSTATE:[established|unestablished] [/DELAY:[DELAY] /JITTER:[JITTER] /LOSS:[LOSS]]
+	cmd := s.hubCommand(command, args...)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// DeleteAccessRule executes vpncmd and deletes a rule from the access list of a specific Hub.
+func (s SoftEther) DeleteAccessRule(ctx context.Context, id int) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd AccessDelete [ID]
+	cmd := s.hubCommand(
+		"AccessDelete",
+		strconv.Itoa(id),
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// EnableAccessRule executes vpncmd and enables a rule of the access list of a specific Hub.
+func (s SoftEther) EnableAccessRule(ctx context.Context, id int) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd AccessEnable [ID]
+	cmd := s.hubCommand(
+		"AccessEnable",
+		strconv.Itoa(id),
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// DisableAccessRule executes vpncmd and disables a rule of the access list of a specific Hub,
+// without deleting it.
+func (s SoftEther) DisableAccessRule(ctx context.Context, id int) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd AccessDisable [ID]
+	cmd := s.hubCommand(
+		"AccessDisable",
+		strconv.Itoa(id),
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
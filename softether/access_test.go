@@ -0,0 +1,250 @@
+package softether_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether/softethertest"
+)
+
+func TestAccessCommands(t *testing.T) {
+	// Arguments of AccessAdd for a rule which matches any packet
+	any := func(command, action string, extra ...string) []string {
+		args := []string{command, action,
+			"/MEMO:", "/PRIORITY:0", "/SRCUSERNAME:", "/DESTUSERNAME:", "/SRCMAC:", "/DESTMAC:",
+			"/SRCIP:0.0.0.0/0", "/DESTIP:0.0.0.0/0", "/PROTOCOL:ip", "/SRCPORT:", "/DESTPORT:", "/TCPSTATE:",
+		}
+		return append(args, extra...)
+	}
+
+	runCommandTests(t, []commandTest{
+		{
+			name: "ListAccessRules",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.ListAccessRules(ctx)
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"AccessList"}},
+			errno: 8, // ERR_HUB_NOT_FOUND
+		},
+		{
+			name: "AddAccessRule",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.AddAccessRule(ctx, softether.AccessRule{
+					Action:        softether.AccessPass,
+					Priority:      100,
+					Description:   "Web access",
+					SrcIP:         "192.168.30.0",
+					SrcMask:       "24",
+					DestIP:        "93.184.216.34",
+					Protocol:      "tcp",
+					DestPortStart: 80,
+					DestPortEnd:   443,
+					SrcUsername:   "1",
+					SrcMAC:        "00-AC-11-22-33-44",
+					SrcMACMask:    "FF-FF-FF-00-00-00",
+					TCPState:      softether.TCPEstablished,
+				})
+			},
+			hub: "subspace",
+			want: [][]string{{"AccessAdd", "pass",
+				"/MEMO:Web access", "/PRIORITY:100", "/SRCUSERNAME:1", "/DESTUSERNAME:",
+				"/SRCMAC:00-AC-11-22-33-44/FF-FF-FF-00-00-00", "/DESTMAC:",
+				"/SRCIP:192.168.30.0/24", "/DESTIP:93.184.216.34/32", "/PROTOCOL:tcp",
+				"/SRCPORT:", "/DESTPORT:80-443", "/TCPSTATE:established",
+			}},
+			errno: 62, // ERR_TOO_MANY_ACCESS_LIST
+		},
+		{
+			name: "AddAccessRule any",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.AddAccessRule(ctx, softether.AccessRule{Action: softether.AccessDiscard})
+			},
+			hub:   "subspace",
+			want:  [][]string{any("AccessAdd", "discard")},
+			errno: 62, // ERR_TOO_MANY_ACCESS_LIST
+		},
+		{
+			name: "AddAccessRule IPv6",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.AddAccessRule(ctx, softether.AccessRule{
+					Action:        softether.AccessDiscard,
+					IPv6:          true,
+					DestIP:        "2001:db8::",
+					DestMask:      "64",
+					Protocol:      "udp",
+					SrcPortStart:  53,
+					DestPortStart: 1024,
+					DestPortEnd:   1024,
+				})
+			},
+			hub: "subspace",
+			want: [][]string{{"AccessAdd6", "discard",
+				"/MEMO:", "/PRIORITY:0", "/SRCUSERNAME:", "/DESTUSERNAME:", "/SRCMAC:", "/DESTMAC:",
+				"/SRCIP:::/0", "/DESTIP:2001:db8::/64", "/PROTOCOL:udp", "/SRCPORT:53", "/DESTPORT:1024", "/TCPSTATE:",
+			}},
+			errno: 62, // ERR_TOO_MANY_ACCESS_LIST
+		},
+		{
+			name: "AddAccessRule network simulation",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.AddAccessRule(ctx, softether.AccessRule{Action: softether.AccessPass, Delay: 100, Jitter: 10, Loss: 5})
+			},
+			hub:   "subspace",
+			want:  [][]string{any("AccessAddEx", "pass", "/DELAY:100", "/JITTER:10", "/LOSS:5")},
+			errno: 38, // ERR_INVALID_PARAMETER
+		},
+		{
+			name: "AddAccessRule IPv6 network simulation",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.AddAccessRule(ctx, softether.AccessRule{Action: softether.AccessPass, IPv6: true, Loss: 1})
+			},
+			hub: "subspace",
+			want: [][]string{{"AccessAddEx6", "pass",
+				"/MEMO:", "/PRIORITY:0", "/SRCUSERNAME:", "/DESTUSERNAME:", "/SRCMAC:", "/DESTMAC:",
+				"/SRCIP:::/0", "/DESTIP:::/0", "/PROTOCOL:ip", "/SRCPORT:", "/DESTPORT:", "/TCPSTATE:",
+				"/DELAY:0", "/JITTER:0", "/LOSS:1",
+			}},
+			errno: 38, // ERR_INVALID_PARAMETER
+		},
+		{
+			name: "DeleteAccessRule",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.DeleteAccessRule(ctx, 2)
+			},
+			hub:   "subspace",
+			want:  [][]string{{"AccessDelete", "2"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "EnableAccessRule",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.EnableAccessRule(ctx, 2)
+			},
+			hub:   "subspace",
+			want:  [][]string{{"AccessEnable", "2"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "DisableAccessRule",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.DisableAccessRule(ctx, 1)
+			},
+			hub:   "subspace",
+			want:  [][]string{{"AccessDisable", "1"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+	})
+}
+
+func TestListAccessRules(t *testing.T) {
+	s, _ := newServer()
+
+	ruleList, err := s.ListAccessRules(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []softether.AccessRule{
+		{
+			ID:            1,
+			Action:        softether.AccessPass,
+			Enabled:       true,
+			Priority:      100,
+			Description:   "Web access",
+			SrcIP:         "192.168.30.0",
+			SrcMask:       "255.255.255.0",
+			Protocol:      "tcp",
+			DestPortStart: 80,
+			DestPortEnd:   443,
+			TCPState:      softether.TCPEstablished,
+		},
+		{
+			ID:          2,
+			Action:      softether.AccessDiscard,
+			Priority:    200,
+			IPv6:        true,
+			SrcUsername: "1",
+			DestIP:      "2001:db8::",
+			DestMask:    "ffff:ffff:ffff:ffff::",
+		},
+		{
+			ID:           3,
+			Action:       softether.AccessPass,
+			Enabled:      true,
+			Priority:     300,
+			Description:  "Slow link",
+			Protocol:     "udp",
+			SrcPortStart: 5000,
+			SrcPortEnd:   5000,
+			Delay:        100,
+			Jitter:       10,
+			Loss:         5,
+		},
+	}
+	if !reflect.DeepEqual(ruleList, want) {
+		t.Errorf("ruleList = %+v, want %+v", ruleList, want)
+	}
+}
+
+func TestParseAccessContents(t *testing.T) {
+	tests := []struct {
+		contents string
+		want     softether.AccessRule
+	}{
+		{
+			contents: "(ipv4) DstIPv4=10.0.0.1/255.255.255.255, Protocol=ICMPv4, Unestablished",
+			want: softether.AccessRule{
+				DestIP:   "10.0.0.1",
+				DestMask: "255.255.255.255",
+				Protocol: "icmpv4",
+				TCPState: softether.TCPUnestablished,
+			},
+		},
+		{
+			contents: "(ipv4) SrcMac=00-AC-11-22-33-44/FF-FF-FF-FF-FF-FF, DstMac=00-AC-55-66-77-88/FF-FF-FF-00-00-00, DstUser=2",
+			want: softether.AccessRule{
+				SrcMAC:       "00-AC-11-22-33-44",
+				SrcMACMask:   "FF-FF-FF-FF-FF-FF",
+				DestMAC:      "00-AC-55-66-77-88",
+				DestMACMask:  "FF-FF-FF-00-00-00",
+				DestUsername: "2",
+			},
+		},
+		{
+			contents: "(ipv6) SrcIPv6=fe80::/ffc0::, Protocol=17, SrcPort=1-1023",
+			want: softether.AccessRule{
+				IPv6:         true,
+				SrcIP:        "fe80::",
+				SrcMask:      "ffc0::",
+				Protocol:     "17",
+				SrcPortStart: 1,
+				SrcPortEnd:   1023,
+			},
+		},
+		{
+			contents: "-",
+		},
+	}
+
+	for _, test := range tests {
+		s, runner := newServer()
+		runner.Handle("AccessList", softethertest.Response{
+			Stdout: "ID,Action,Status,Priority,Memo,Contents\n1,Pass,Enabled,1,-,\"" + test.contents + "\"\n",
+		})
+
+		ruleList, err := s.ListAccessRules(context.Background())
+		if err != nil || len(ruleList) != 1 {
+			t.Fatalf("%s: ruleList, err = %v, %v", test.contents, ruleList, err)
+		}
+
+		want := test.want
+		want.ID, want.Action, want.Enabled, want.Priority = 1, softether.AccessPass, true, 1
+		if !reflect.DeepEqual(ruleList[0], want) {
+			t.Errorf("%s: rule = %+v, want %+v", test.contents, ruleList[0], want)
+		}
+	}
+}
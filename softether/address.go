@@ -0,0 +1,81 @@
+package softether
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultPort is the administration port used when SoftEther.Port is zero.
+const DefaultPort = 992
+
+// AdminPorts are the ports a SoftEther server listens on for administration by
+// default, in the order DetectPort tries them.
+var AdminPorts = []int{443, 992, 5555}
+
+var reHostname = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?)*\.?$`)
+
+// host returns the host of s, without the brackets of IPv6 addresses.
+func (s SoftEther) host() (string, error) {
+	host := strings.TrimSpace(s.IP)
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		host = host[1 : len(host)-1]
+	}
+
+	switch {
+	case host == "":
+		return "", fmt.Errorf("no host")
+	case net.ParseIP(host) != nil:
+		return host, nil
+	case len(host) > 253 || !reHostname.MatchString(host):
+		return "", fmt.Errorf("invalid host %q", s.IP)
+	}
+
+	return host, nil
+}
+
+// Address returns the "host:port" address vpncmd connects to. IP may be an IPv4
+// address, an IPv6 address with or without brackets, or a hostname. Port defaults
+// to DefaultPort.
+func (s SoftEther) Address() (string, error) {
+	host, err := s.host()
+	if err != nil {
+		return "", err
+	}
+
+	port := s.Port
+	if port == 0 {
+		port = DefaultPort
+	}
+	if port < 0 || port > 65535 {
+		return "", fmt.Errorf("invalid port %d", s.Port)
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(port)), nil
+}
+
+// DetectPort tries to connect to each of AdminPorts in order and returns the first
+// port which accepts the connection. It fails with ErrConnectFailed if none does.
+func (s SoftEther) DetectPort(ctx context.Context) (port int, err error) {
+	host, err := s.host()
+	if err != nil {
+		return 0, NewKindError(KindInvalidAddress, "", err)
+	}
+
+	dialer := net.Dialer{Timeout: s.Timeout}
+	for _, port = range AdminPorts {
+		conn, dialErr := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if dialErr == nil {
+			conn.Close()
+			return port, nil
+		}
+		err = dialErr
+	}
+
+	connectError := NewError(1) // ERR_CONNECT_FAILED
+	connectError.Err = err
+	return 0, connectError
+}
@@ -0,0 +1,22 @@
+package softether
+
+import "context"
+
+// API is the set of management calls offered by both the vpncmd backend (SoftEther)
+// and the JSON-RPC backend (jsonrpc.Client), so that callers can switch between them.
+type API interface {
+	GetServerStatus(ctx context.Context) (ServerStatus, error)
+	GetSessionList(ctx context.Context) ([]Session, error)
+	GetSessionInfo(ctx context.Context, sessionName string) (Session, error)
+	DisconnectSession(ctx context.Context, sessionName string) error
+	GetUserList(ctx context.Context) ([]User, error)
+	GetUserInfo(ctx context.Context, id string) (User, error)
+	CreateUser(ctx context.Context, args ...interface{}) error
+	SetUserPassword(ctx context.Context, id string, password string) error
+	SetUserInfo(ctx context.Context, id, email, description string) error
+	SetUserEnabled(ctx context.Context, username string, enabled bool) error
+	DeleteUser(ctx context.Context, id string) error
+	SetPreSharedKey(ctx context.Context, preSharedKey string) error
+}
+
+var _ API = SoftEther{}
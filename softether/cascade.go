@@ -0,0 +1,283 @@
+package softether
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CascadeState is the connection state of a cascade connection.
+type CascadeState string
+
+// Cascade connection states.
+const (
+	CascadeOffline    CascadeState = "Offline"
+	CascadeOnline     CascadeState = "Online"
+	CascadeConnecting CascadeState = "Connecting"
+	CascadeError      CascadeState = "Error" // The last connection attempt failed, see LastError
+)
+
+// Cascade is a cascade connection from a Virtual Hub to a Virtual Hub on another server,
+// as listed by ListCascades.
+type Cascade struct {
+	Name          string
+	State         CascadeState
+	EstablishedAt time.Time
+	Server        string
+	Hub           string
+	LastError     error // The *Error of the last connection attempt if State is CascadeError
+}
+
+// CascadeStatus is the current status of an online cascade connection.
+type CascadeStatus struct {
+	Name                string
+	State               CascadeState // CascadeOnline once the session is established, otherwise CascadeConnecting
+	SessionStatus       string
+	ServerName          string
+	ServerPort          int
+	ServerProduct       string
+	ServerVersion       string
+	ServerBuild         string
+	Encryption          string
+	Encrypted           bool
+	Compressed          bool
+	HalfDuplex          bool
+	UDPAcceleration     bool
+	TCPConnections      int
+	NumberOfEstablished int
+	ConnectionStarted   time.Time
+	FirstEstablished    time.Time
+	CurrentEstablished  time.Time
+	Traffic
+}
+
+var reCascadeError = regexp.MustCompile(`^Error (\d+)`)
+
+// parseCascade converts a row of CascadeList. Failed connections are shown as
+// "Error 9: User authentication failed."; the code is resolved with NewError.
+func parseCascade(m map[string]string) Cascade {
+	cascade := Cascade{
+		Name:          parseString(m["Setting Name"]),
+		EstablishedAt: parseTime(m["Established at"]),
+		Server:        parseString(m["Destination VPN Server"]),
+		Hub:           parseString(m["Virtual Hub"]),
+	}
+
+	status := parseString(m["Status"])
+	switch {
+	case status == "Offline":
+		cascade.State = CascadeOffline
+	case strings.HasPrefix(status, "Online"):
+		cascade.State = CascadeOnline
+	case strings.HasPrefix(status, "Error"):
+		cascade.State = CascadeError
+		if match := reCascadeError.FindStringSubmatch(status); match != nil {
+			cascade.LastError = NewError(parseInt(match[1]))
+		}
+	default:
+		cascade.State = CascadeConnecting
+	}
+
+	return cascade
+}
+
+// parseCascadeStatus converts the output table of CascadeStatusGet.
+func parseCascadeStatus(m map[string]string) CascadeStatus {
+	status := CascadeStatus{
+		Name:                parseString(m["VPN Connection Setting Name"]),
+		State:               CascadeConnecting,
+		SessionStatus:       parseString(m["Session Status"]),
+		ServerName:          parseString(m["Server Name"]),
+		ServerPort:          parseInt(m["Port Number"]),
+		ServerProduct:       parseString(m["Server Product Name"]),
+		ServerVersion:       parseString(m["Server Version"]),
+		ServerBuild:         parseString(m["Server Build"]),
+		Encryption:          parseString(m["Encryption"]),
+		Encrypted:           parseBool(m["Encryption"]),
+		Compressed:          parseBool(m["Use of Compression"]),
+		HalfDuplex:          parseBool(m["Half Duplex TCP Connection Mode"]),
+		UDPAcceleration:     parseBool(m["UDP Acceleration is Active"]),
+		TCPConnections:      parseInt(m["Number of TCP Connections"]),
+		NumberOfEstablished: int(parseCount(m["Number of Established Sessions"])),
+		ConnectionStarted:   parseTime(m["Connection Started at"]),
+		FirstEstablished:    parseTime(m["First Session has been Established since"]),
+		CurrentEstablished:  parseTime(m["Current Session has been Established since"]),
+		Traffic:             parseTraffic(m),
+	}
+
+	if strings.Contains(status.SessionStatus, "Established") {
+		status.State = CascadeOnline
+	}
+
+	return status
+}
+
+// ListCascades executes vpncmd and gets the cascade connections of a specific Hub.
+func (s SoftEther) ListCascades(ctx context.Context) (cascadeList []Cascade, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd CascadeList
+	cmd := s.hubCommand("CascadeList")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	rows, err := parseTable(cmd, cmdOutput, "Setting Name")
+	for _, row := range rows {
+		cascadeList = append(cascadeList, parseCascade(row))
+	}
+
+	return
+}
+
+// CreateCascade executes vpncmd and creates an offline cascade connection from a specific Hub
+// to the Virtual Hub remoteHub on the server at server:port. The connection uses anonymous
+// authentication until SetCascadePassword or SetCascadeCert is called.
+func (s SoftEther) CreateCascade(ctx context.Context, name, server string, port int, remoteHub, username string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd CascadeCreate [NAME] /SERVER:[SERVER]:[PORT] /HUB:[REMOTE_HUB] /USERNAME:[USERNAME]
+	cmd := s.hubCommand(
+		"CascadeCreate",
+		name,
+		"/SERVER:"+net.JoinHostPort(server, strconv.Itoa(port)),
+		"/HUB:"+remoteHub,
+		"/USERNAME:"+username,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// SetCascadePassword executes vpncmd and makes a cascade connection of a specific Hub
+// authenticate with a password, which the remote server checks itself or, if radius
+// is true, through RADIUS or NT domain authentication.
+func (s SoftEther) SetCascadePassword(ctx context.Context, name, password string, radius bool) (err error) {
+	authType := "standard"
+	if radius {
+		authType = "radius"
+	}
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /in:[SCRIPT], SCRIPT: CascadePasswordSet [NAME] /PASSWORD:[PASSWORD] /TYPE:[standard|radius]
+	cmd := s.hubCommand(
+		"CascadePasswordSet",
+		name,
+		"/PASSWORD:"+password,
+		"/TYPE:"+authType,
+	)
+	cmd.Secrets = []int{1}
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// SetCascadeCert executes vpncmd and makes a cascade connection of a specific Hub
+// authenticate with the leaf certificate and private key of cert. They are handed to
+// vpncmd through temporary files, readable only by the current user.
+func (s SoftEther) SetCascadeCert(ctx context.Context, name string, cert tls.Certificate) (err error) {
+	dir, err := tempDir("CascadeCertSet")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile, err := writeKeyPair("CascadeCertSet", dir, cert)
+	if err != nil {
+		return
+	}
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd CascadeCertSet [NAME] /LOADCERT:[CERT_FILE] /LOADKEY:[KEY_FILE]
+	cmd := s.hubCommand(
+		"CascadeCertSet",
+		name,
+		"/LOADCERT:"+certFile,
+		"/LOADKEY:"+keyFile,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// SetCascadeOnline executes vpncmd and starts a cascade connection of a specific Hub.
+// The server keeps retrying until the connection is established; check the progress
+// with ListCascades or GetCascadeStatus.
+func (s SoftEther) SetCascadeOnline(ctx context.Context, name string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd CascadeOnline [NAME]
+	cmd := s.hubCommand(
+		"CascadeOnline",
+		name,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// SetCascadeOffline executes vpncmd and stops a cascade connection of a specific Hub.
+func (s SoftEther) SetCascadeOffline(ctx context.Context, name string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd CascadeOffline [NAME]
+	cmd := s.hubCommand(
+		"CascadeOffline",
+		name,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// GetCascadeStatus executes vpncmd and gets the status of a cascade connection of a specific Hub.
+// It fails with ErrLinkIsOffline if the connection is offline.
+func (s SoftEther) GetCascadeStatus(ctx context.Context, name string) (status CascadeStatus, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd CascadeStatusGet [NAME]
+	cmd := s.hubCommand(
+		"CascadeStatusGet",
+		name,
+	)
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	statusMap, err := parseKeyValue(cmd, cmdOutput)
+	if err != nil {
+		return
+	}
+
+	status = parseCascadeStatus(statusMap)
+	return
+}
+
+// DeleteCascade executes vpncmd and deletes a cascade connection of a specific Hub.
+func (s SoftEther) DeleteCascade(ctx context.Context, name string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd CascadeDelete [NAME]
+	cmd := s.hubCommand(
+		"CascadeDelete",
+		name,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
@@ -0,0 +1,211 @@
+package softether_test
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+)
+
+func TestCascadeCommands(t *testing.T) {
+	runCommandTests(t, []commandTest{
+		{
+			name: "ListCascades",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.ListCascades(ctx)
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"CascadeList"}},
+			errno: 8, // ERR_HUB_NOT_FOUND
+		},
+		{
+			name: "CreateCascade",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.CreateCascade(ctx, "branch-tokyo", "tokyo.example.com", 443, "branch", "subspace")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"CascadeCreate", "branch-tokyo", "/SERVER:tokyo.example.com:443", "/HUB:branch", "/USERNAME:subspace"}},
+			errno: 59, // ERR_LINK_ALREADY_EXISTS
+		},
+		{
+			name: "CreateCascade IPv6",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.CreateCascade(ctx, "branch-v6", "2001:db8::1", 5555, "branch", "subspace")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"CascadeCreate", "branch-v6", "/SERVER:[2001:db8::1]:5555", "/HUB:branch", "/USERNAME:subspace"}},
+			errno: 59, // ERR_LINK_ALREADY_EXISTS
+		},
+		{
+			name: "SetCascadePassword",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetCascadePassword(ctx, "branch-tokyo", "cascade-pass", false)
+			},
+			hub:     "subspace",
+			want:    [][]string{{"CascadePasswordSet", "branch-tokyo", "/PASSWORD:cascade-pass", "/TYPE:standard"}},
+			secrets: []string{"cascade-pass"},
+			errno:   29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "SetCascadePassword RADIUS",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetCascadePassword(ctx, "branch-tokyo", "cascade-pass", true)
+			},
+			hub:     "subspace",
+			want:    [][]string{{"CascadePasswordSet", "branch-tokyo", "/PASSWORD:cascade-pass", "/TYPE:radius"}},
+			secrets: []string{"cascade-pass"},
+			errno:   29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "SetCascadeOnline",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetCascadeOnline(ctx, "branch-tokyo")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"CascadeOnline", "branch-tokyo"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "SetCascadeOffline",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetCascadeOffline(ctx, "branch-tokyo")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"CascadeOffline", "branch-tokyo"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "GetCascadeStatus",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.GetCascadeStatus(ctx, "branch-tokyo")
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"CascadeStatusGet", "branch-tokyo"}},
+			errno: 61, // ERR_LINK_IS_OFFLINE
+		},
+		{
+			name: "DeleteCascade",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.DeleteCascade(ctx, "branch-tokyo")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"CascadeDelete", "branch-tokyo"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+	})
+}
+
+func TestListCascades(t *testing.T) {
+	s, _ := newServer()
+
+	cascadeList, err := s.ListCascades(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if len(cascadeList) != 3 {
+		t.Fatalf("cascadeList = %+v, want 3 cascades", cascadeList)
+	}
+
+	// The error of the failed connection is resolved from its code
+	lastError := cascadeList[1].LastError
+	checkError(t, lastError, 9)
+	if !errors.Is(lastError, softether.ErrAuthFailed) {
+		t.Errorf("LastError = %v, want ErrAuthFailed", lastError)
+	}
+	cascadeList[1].LastError = nil
+
+	want := []softether.Cascade{
+		{
+			Name:          "branch-tokyo",
+			State:         softether.CascadeOnline,
+			EstablishedAt: date("2017-04-20 09:00:00"),
+			Server:        "tokyo.example.com",
+			Hub:           "branch",
+		},
+		{Name: "branch-osaka", State: softether.CascadeError, Server: "osaka.example.com", Hub: "branch"},
+		{Name: "branch-nagoya", State: softether.CascadeOffline, Server: "nagoya.example.com", Hub: "branch"},
+	}
+	if !reflect.DeepEqual(cascadeList, want) {
+		t.Errorf("cascadeList = %+v, want %+v", cascadeList, want)
+	}
+}
+
+func TestGetCascadeStatus(t *testing.T) {
+	s, _ := newServer()
+
+	status, err := s.GetCascadeStatus(context.Background(), "branch-tokyo")
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := softether.CascadeStatus{
+		Name:                "branch-tokyo",
+		State:               softether.CascadeOnline,
+		SessionStatus:       "Connection Completed (Session Established)",
+		ServerName:          "tokyo.example.com",
+		ServerPort:          443,
+		ServerProduct:       "SoftEther VPN Server (64 bit)",
+		ServerVersion:       "4.22",
+		ServerBuild:         "Build 9634",
+		Encryption:          "Enabled (Algorithm: AES128-SHA)",
+		Encrypted:           true,
+		UDPAcceleration:     true,
+		TCPConnections:      2,
+		NumberOfEstablished: 1,
+		ConnectionStarted:   date("2017-04-20 08:59:58"),
+		FirstEstablished:    date("2017-04-20 09:00:00"),
+		CurrentEstablished:  date("2017-04-20 09:00:00"),
+		Traffic: softether.Traffic{
+			OutgoingUnicastPackets:   1200,
+			OutgoingUnicastBytes:     345678,
+			OutgoingBroadcastPackets: 12,
+			OutgoingBroadcastBytes:   1024,
+			IncomingUnicastPackets:   2300,
+			IncomingUnicastBytes:     456789,
+			IncomingBroadcastPackets: 23,
+			IncomingBroadcastBytes:   2048,
+		},
+	}
+	if status != want {
+		t.Errorf("status = %+v, want %+v", status, want)
+	}
+}
+
+func TestSetCascadeCert(t *testing.T) {
+	cert := newKeyPair(t, "branch-tokyo")
+
+	runner := newLoadRunner()
+	s := softether.SoftEther{IP: "10.0.0.1", Password: "subspace", Hub: "subspace", Runner: runner}
+	if err := s.SetCascadeCert(context.Background(), "branch-tokyo", cert); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	commands := runner.Commands()
+	if len(commands) != 1 {
+		t.Fatalf("commands = %v, want CascadeCertSet", commands)
+	}
+	cmd := commands[0]
+	if cmd.Name != "CascadeCertSet" || cmd.Hub != "subspace" || len(cmd.Args) != 3 || cmd.Args[0] != "branch-tokyo" ||
+		!strings.HasPrefix(cmd.Args[1], "/LOADCERT:") || !strings.HasPrefix(cmd.Args[2], "/LOADKEY:") {
+		t.Errorf("command = %s %q on Hub %q", cmd.Name, cmd.Args, cmd.Hub)
+	}
+	checkKeyPair(t, runner, cert)
+
+	// Without a certificate vpncmd is not run
+	s, plain := newServer()
+	err := s.SetCascadeCert(context.Background(), "branch-tokyo", tls.Certificate{})
+	checkError(t, err, 38)
+	if len(plain.Commands()) != 0 {
+		t.Errorf("commands = %v, want none", plain.Commands())
+	}
+
+	s, plain = newServer()
+	plain.Fail("CascadeCertSet", 29)
+	checkError(t, s.SetCascadeCert(context.Background(), "branch-tokyo", cert), 29)
+}
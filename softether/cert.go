@@ -0,0 +1,300 @@
+package softether
+
+import (
+	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// TrustedCA is a certificate in the list of trusted CAs of a Virtual Hub, as listed by
+// ListTrustedCAs. Users with signed certificate authentication need a certificate signed by one of them.
+type TrustedCA struct {
+	ID        int
+	IssuedTo  string
+	Issuer    string
+	ExpiresAt time.Time
+}
+
+// parseTrustedCA converts a row of CAList.
+func parseTrustedCA(m map[string]string) TrustedCA {
+	return TrustedCA{
+		ID:        parseInt(m["ID"]),
+		IssuedTo:  parseString(m["Issued to"]),
+		Issuer:    parseString(m["Issuer"]),
+		ExpiresAt: parseTime(m["Expiration"]),
+	}
+}
+
+// parsePEM returns the DER bytes of the first PEM block of data, or data itself if it is not PEM encoded.
+func parsePEM(data []byte) []byte {
+	if block, _ := pem.Decode(data); block != nil {
+		return block.Bytes
+	}
+	return data
+}
+
+// parsePrivateKey parses a PKCS #1, PKCS #8 or SEC 1 private key in PEM or DER form.
+func parsePrivateKey(data []byte) (key crypto.PrivateKey, err error) {
+	der := parsePEM(data)
+	if key, err = x509.ParsePKCS1PrivateKey(der); err == nil {
+		return
+	}
+	if key, err = x509.ParsePKCS8PrivateKey(der); err == nil {
+		return
+	}
+	return x509.ParseECPrivateKey(der)
+}
+
+// tempDir creates a directory, which only the current user can access, for files
+// vpncmd reads or writes. The caller removes it.
+func tempDir(command string) (dir string, err error) {
+	dir, err = os.MkdirTemp("", "vpncmd-*")
+	if err != nil {
+		return "", NewKindError(KindExec, command, err)
+	}
+	return dir, nil
+}
+
+// readCertFile parses the certificate vpncmd saved to path for cmd.
+func readCertFile(cmd Command, path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, parseFailure(cmd, "no certificate saved: %v", err)
+	}
+	cert, err := x509.ParseCertificate(parsePEM(data))
+	if err != nil {
+		return nil, parseFailure(cmd, "invalid certificate: %v", err)
+	}
+	return cert, nil
+}
+
+// writeKeyPair writes the leaf certificate and private key of cert to dir as PEM files,
+// readable only by the current user, for the vpncmd command to load.
+func writeKeyPair(command, dir string, cert tls.Certificate) (certFile, keyFile string, err error) {
+	if len(cert.Certificate) == 0 {
+		return "", "", invalidParameter(command, errors.New("no certificate"))
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		return "", "", invalidParameter(command, err)
+	}
+
+	certFile = filepath.Join(dir, "cert.cer")
+	keyFile = filepath.Join(dir, "cert.key")
+	if err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0600); err != nil {
+		return "", "", NewKindError(KindExec, command, err)
+	}
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return "", "", NewKindError(KindExec, command, err)
+	}
+	return certFile, keyFile, nil
+}
+
+// GetServerCert executes vpncmd and gets the TLS certificate of the SoftEther server.
+// vpncmd hands the certificate over through a temporary file.
+func (s SoftEther) GetServerCert(ctx context.Context) (cert *x509.Certificate, err error) {
+	dir, err := tempDir("ServerCertGet")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+	certFile := filepath.Join(dir, "server.cer")
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /cmd ServerCertGet [CERT_FILE]
+	cmd := s.command(
+		"ServerCertGet",
+		certFile,
+	)
+
+	// Execute
+	if _, err = s.execute(ctx, cmd); err != nil {
+		return
+	}
+
+	// Extract data
+	return readCertFile(cmd, certFile)
+}
+
+// GetServerKey executes vpncmd and gets the private key of the TLS certificate of the
+// SoftEther server, e.g. an *rsa.PrivateKey. vpncmd hands the key over through a temporary
+// file, readable only by the current user.
+func (s SoftEther) GetServerKey(ctx context.Context) (key crypto.PrivateKey, err error) {
+	dir, err := tempDir("ServerKeyGet")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+	keyFile := filepath.Join(dir, "server.key")
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /cmd ServerKeyGet [KEY_FILE]
+	cmd := s.command(
+		"ServerKeyGet",
+		keyFile,
+	)
+
+	// Execute
+	if _, err = s.execute(ctx, cmd); err != nil {
+		return
+	}
+
+	// Extract data
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, parseFailure(cmd, "no private key saved: %v", err)
+	}
+	if key, err = parsePrivateKey(data); err != nil {
+		return nil, parseFailure(cmd, "invalid private key: %v", err)
+	}
+	return
+}
+
+// SetServerCert executes vpncmd and replaces the TLS certificate of the SoftEther server
+// with the leaf certificate and private key of cert; intermediate certificates are not
+// used. The certificate and key are handed to vpncmd through temporary files, readable
+// only by the current user.
+func (s SoftEther) SetServerCert(ctx context.Context, cert tls.Certificate) (err error) {
+	dir, err := tempDir("ServerCertSet")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile, err := writeKeyPair("ServerCertSet", dir, cert)
+	if err != nil {
+		return
+	}
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /cmd ServerCertSet /LOADCERT:[CERT_FILE] /LOADKEY:[KEY_FILE]
+	cmd := s.command(
+		"ServerCertSet",
+		"/LOADCERT:"+certFile,
+		"/LOADKEY:"+keyFile,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// SetServerCertPEM is like SetServerCert, but takes a PEM encoded certificate and private key.
+func (s SoftEther) SetServerCertPEM(ctx context.Context, certPEM, keyPEM []byte) (err error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return invalidParameter("ServerCertSet", err)
+	}
+	return s.SetServerCert(ctx, cert)
+}
+
+// RegenerateServerCert executes vpncmd and replaces the TLS certificate of the SoftEther
+// server with a new self-signed certificate for commonName.
+func (s SoftEther) RegenerateServerCert(ctx context.Context, commonName string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /cmd ServerCertRegenerate [CN]
+	cmd := s.command(
+		"ServerCertRegenerate",
+		commonName,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// ListTrustedCAs executes vpncmd and gets the trusted CA certificates of a specific Hub.
+func (s SoftEther) ListTrustedCAs(ctx context.Context) (caList []TrustedCA, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd CAList
+	cmd := s.hubCommand("CAList")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	rows, err := parseTable(cmd, cmdOutput, "ID")
+	for _, row := range rows {
+		caList = append(caList, parseTrustedCA(row))
+	}
+
+	return
+}
+
+// AddTrustedCA executes vpncmd and adds a CA certificate to the trusted CAs of a specific Hub.
+// The certificate is handed to vpncmd through a temporary file.
+func (s SoftEther) AddTrustedCA(ctx context.Context, cert *x509.Certificate) (err error) {
+	dir, err := tempDir("CAAdd")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+	certFile := filepath.Join(dir, "ca.cer")
+
+	if err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0600); err != nil {
+		return NewKindError(KindExec, "CAAdd", err)
+	}
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd CAAdd [CERT_FILE]
+	cmd := s.hubCommand(
+		"CAAdd",
+		certFile,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// DeleteTrustedCA executes vpncmd and removes a CA certificate from the trusted CAs of a specific Hub.
+func (s SoftEther) DeleteTrustedCA(ctx context.Context, id int) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd CADelete [ID]
+	cmd := s.hubCommand(
+		"CADelete",
+		strconv.Itoa(id),
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// GetTrustedCA executes vpncmd and gets a CA certificate of the trusted CAs of a specific Hub.
+// vpncmd hands the certificate over through a temporary file.
+func (s SoftEther) GetTrustedCA(ctx context.Context, id int) (cert *x509.Certificate, err error) {
+	dir, err := tempDir("CAGet")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+	certFile := filepath.Join(dir, "ca.cer")
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd CAGet [ID] /SAVECERT:[CERT_FILE]
+	cmd := s.hubCommand(
+		"CAGet",
+		strconv.Itoa(id),
+		"/SAVECERT:"+certFile,
+	)
+
+	// Execute
+	if _, err = s.execute(ctx, cmd); err != nil {
+		return
+	}
+
+	// Extract data
+	return readCertFile(cmd, certFile)
+}
@@ -0,0 +1,277 @@
+package softether_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether/softethertest"
+)
+
+func TestCertCommands(t *testing.T) {
+	runCommandTests(t, []commandTest{
+		{
+			name: "RegenerateServerCert",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.RegenerateServerCert(ctx, "vpn.subspace.example")
+			},
+			want:  [][]string{{"ServerCertRegenerate", "vpn.subspace.example"}},
+			errno: 52, // ERR_NOT_ENOUGH_RIGHT
+		},
+		{
+			name: "ListTrustedCAs",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.ListTrustedCAs(ctx)
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"CAList"}},
+			errno: 8, // ERR_HUB_NOT_FOUND
+		},
+		{
+			name: "DeleteTrustedCA",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.DeleteTrustedCA(ctx, 1)
+			},
+			hub:   "subspace",
+			want:  [][]string{{"CADelete", "1"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+	})
+}
+
+// checkSaved fails t unless runner ran exactly the vpncmd command name with args on hub,
+// followed by the file vpncmd saves to, prefixed with param, which is gone by now.
+func checkSaved(t *testing.T, runner *softethertest.Runner, hub, name string, args []string, param string) {
+	t.Helper()
+
+	commands := runner.Commands()
+	if len(commands) != 1 {
+		t.Fatalf("commands = %v, want %s", commands, name)
+	}
+	cmd := commands[0]
+	n := len(cmd.Args) - 1
+	if cmd.Name != name || cmd.Hub != hub || n != len(args) || !strings.HasPrefix(cmd.Args[n], param) ||
+		(n > 0 && !reflect.DeepEqual(cmd.Args[:n], args)) {
+		t.Fatalf("command = %s %q on Hub %q", cmd.Name, cmd.Args, cmd.Hub)
+	}
+	path := strings.TrimPrefix(cmd.Args[n], param)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("%s is left behind: %v", path, err)
+	}
+}
+
+func TestGetServerCert(t *testing.T) {
+	s, runner := newServer()
+
+	cert, err := s.GetServerCert(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if cert.Subject.CommonName != "vpn.subspace.example" {
+		t.Errorf("CommonName = %q", cert.Subject.CommonName)
+	}
+	checkSaved(t, runner, "", "ServerCertGet", nil, "")
+
+	// Failures
+	s, runner = newServer()
+	runner.Fail("ServerCertGet", 52)
+	_, err = s.GetServerCert(context.Background())
+	checkError(t, err, 52)
+
+	for _, file := range []string{"", "not a certificate"} {
+		s, runner = newServer()
+		runner.Handle("ServerCertGet", softethertest.Response{File: file})
+		_, err = s.GetServerCert(context.Background())
+		checkKind(t, err, softether.KindParse)
+	}
+}
+
+func TestGetServerKey(t *testing.T) {
+	s, runner := newServer()
+
+	key, err := s.GetServerKey(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	checkSaved(t, runner, "", "ServerKeyGet", nil, "")
+
+	// The key belongs to the server certificate
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		t.Fatalf("key = %T, want *rsa.PrivateKey", key)
+	}
+	block, _ := pem.Decode([]byte(softethertest.ServerCert))
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rsaKey.PublicKey.Equal(cert.PublicKey) {
+		t.Errorf("key does not match the server certificate")
+	}
+
+	// PKCS #8 and DER forms are read as well
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})), string(pkcs8)} {
+		s, runner = newServer()
+		runner.Handle("ServerKeyGet", softethertest.Response{File: file})
+		if key, err := s.GetServerKey(context.Background()); err != nil || !rsaKey.Equal(key) {
+			t.Errorf("key, err = %v, %v", key, err)
+		}
+	}
+
+	// Failures
+	s, runner = newServer()
+	runner.Fail("ServerKeyGet", 52)
+	_, err = s.GetServerKey(context.Background())
+	checkError(t, err, 52)
+
+	for _, file := range []string{"", "not a key"} {
+		s, runner = newServer()
+		runner.Handle("ServerKeyGet", softethertest.Response{File: file})
+		_, err = s.GetServerKey(context.Background())
+		checkKind(t, err, softether.KindParse)
+	}
+}
+
+func TestSetServerCert(t *testing.T) {
+	cert := newKeyPair(t, "vpn.subspace.example")
+
+	runner := newLoadRunner()
+	s := softether.SoftEther{IP: "10.0.0.1", Password: "subspace", Runner: runner}
+	if err := s.SetServerCert(context.Background(), cert); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	commands := runner.Commands()
+	if len(commands) != 1 {
+		t.Fatalf("commands = %v, want ServerCertSet", commands)
+	}
+	cmd := commands[0]
+	if cmd.Name != "ServerCertSet" || cmd.Hub != "" || len(cmd.Args) != 2 ||
+		!strings.HasPrefix(cmd.Args[0], "/LOADCERT:") || !strings.HasPrefix(cmd.Args[1], "/LOADKEY:") {
+		t.Errorf("command = %s %q on Hub %q", cmd.Name, cmd.Args, cmd.Hub)
+	}
+	checkKeyPair(t, runner, cert)
+
+	// Without a certificate vpncmd is not run
+	s, plain := newServer()
+	checkError(t, s.SetServerCert(context.Background(), tls.Certificate{}), 38)
+	if len(plain.Commands()) != 0 {
+		t.Errorf("commands = %v, want none", plain.Commands())
+	}
+
+	s, plain = newServer()
+	plain.Fail("ServerCertSet", 52)
+	checkError(t, s.SetServerCert(context.Background(), cert), 52)
+}
+
+func TestSetServerCertPEM(t *testing.T) {
+	cert, err := tls.X509KeyPair([]byte(softethertest.ServerCert), []byte(softethertest.ServerKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner := newLoadRunner()
+	s := softether.SoftEther{IP: "10.0.0.1", Password: "subspace", Runner: runner}
+	if err := s.SetServerCertPEM(context.Background(), []byte(softethertest.ServerCert), []byte(softethertest.ServerKey)); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	checkKeyPair(t, runner, cert)
+
+	// A key which does not match the certificate is not handed to vpncmd
+	s, plain := newServer()
+	other := newKeyPair(t, "other")
+	otherKey, err := x509.MarshalPKCS8PrivateKey(other.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.SetServerCertPEM(context.Background(), []byte(softethertest.ServerCert), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: otherKey}))
+	checkError(t, err, 38)
+	if len(plain.Commands()) != 0 {
+		t.Errorf("commands = %v, want none", plain.Commands())
+	}
+}
+
+func TestListTrustedCAs(t *testing.T) {
+	s, _ := newServer()
+
+	caList, err := s.ListTrustedCAs(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []softether.TrustedCA{
+		{ID: 1, IssuedTo: "Subspace Root CA", Issuer: "Subspace Root CA", ExpiresAt: date("2037-04-19 00:00:00")},
+	}
+	if !reflect.DeepEqual(caList, want) {
+		t.Errorf("caList = %+v, want %+v", caList, want)
+	}
+}
+
+func TestAddTrustedCA(t *testing.T) {
+	block, _ := pem.Decode([]byte(softethertest.CACert))
+	ca, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner := newLoadRunner()
+	s := softether.SoftEther{IP: "10.0.0.1", Password: "subspace", Hub: "subspace", Runner: runner}
+	if err := s.AddTrustedCA(context.Background(), ca); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	commands := runner.Commands()
+	if len(commands) != 1 || commands[0].Name != "CAAdd" || commands[0].Hub != "subspace" {
+		t.Fatalf("commands = %v, want CAAdd", commands)
+	}
+	file := runner.files[""]
+	if loaded, _ := pem.Decode(file.Data); loaded == nil || !bytes.Equal(loaded.Bytes, ca.Raw) {
+		t.Errorf("loaded %q, want the CA certificate", file.Data)
+	}
+	if file.Mode != 0600 {
+		t.Errorf("%s mode = %v, want 0600", file.Path, file.Mode)
+	}
+	if _, err := os.Stat(file.Path); !os.IsNotExist(err) {
+		t.Errorf("%s is left behind: %v", file.Path, err)
+	}
+
+	s, plain := newServer()
+	plain.Fail("CAAdd", 38)
+	checkError(t, s.AddTrustedCA(context.Background(), ca), 38)
+}
+
+func TestGetTrustedCA(t *testing.T) {
+	s, runner := newServer()
+
+	ca, err := s.GetTrustedCA(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if ca.Subject.CommonName != "Subspace Root CA" || !ca.IsCA {
+		t.Errorf("CommonName, IsCA = %q, %v", ca.Subject.CommonName, ca.IsCA)
+	}
+	checkSaved(t, runner, "subspace", "CAGet", []string{"1"}, "/SAVECERT:")
+
+	s, runner = newServer()
+	runner.Fail("CAGet", 29)
+	_, err = s.GetTrustedCA(context.Background(), 2)
+	checkError(t, err, 29)
+
+	s, runner = newServer()
+	runner.Handle("CAGet", softethertest.Response{File: "not a certificate"})
+	_, err = s.GetTrustedCA(context.Background(), 1)
+	checkKind(t, err, softether.KindParse)
+}
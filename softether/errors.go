@@ -1,6 +1,11 @@
 package softether
 
-var errors = map[int]string{
+import (
+	"fmt"
+	"strings"
+)
+
+var errorNames = map[int]string{
 	0:   "ERR_NO_ERROR",
 	1:   "ERR_CONNECT_FAILED",
 	2:   "ERR_SERVER_IS_NOT_VPN",
@@ -149,7 +154,144 @@ var errors = map[int]string{
 	148: "ERR_VPN_CONNECTION_DISCONNECTED_DUE_TO_SYSTEM_SUSPENSION",
 }
 
+// Kind classifies why a vpncmd command failed.
+type Kind int
+
+const (
+	KindErrorCode      Kind = iota // vpncmd exited with a SoftEther error code
+	KindNotFound                   // the vpncmd binary could not be found
+	KindTimeout                    // the command did not complete in time
+	KindSignal                     // vpncmd was killed by a signal
+	KindParse                      // the output of vpncmd could not be understood
+	KindExec                       // vpncmd could not be run for another reason
+	KindCanceled                   // the context of the command was canceled
+	KindInvalidAddress             // the address of the server is invalid
+	KindCertMismatch               // the server certificate does not match SoftEther.Fingerprint or jsonrpc.Client.Fingerprint
+)
+
+var kindNames = map[Kind]string{
+	KindErrorCode:      "error code",
+	KindNotFound:       "vpncmd not found",
+	KindTimeout:        "timed out",
+	KindSignal:         "killed by signal",
+	KindParse:          "unexpected output",
+	KindExec:           "could not run vpncmd",
+	KindCanceled:       "canceled",
+	KindInvalidAddress: "invalid server address",
+	KindCertMismatch:   "server certificate mismatch",
+}
+
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// Sentinel errors for common SoftEther error codes, for use with errors.Is:
+//
+//	if errors.Is(err, softether.ErrObjectNotFound) { ... }
+var (
+	ErrConnectFailed         = NewError(1)
+	ErrHubNotFound           = NewError(8)
+	ErrAuthFailed            = NewError(9)
+	ErrAccessDenied          = NewError(12)
+	ErrInternalError         = NewError(23)
+	ErrObjectNotFound        = NewError(29)
+	ErrNotSupported          = NewError(33)
+	ErrInvalidParameter      = NewError(38)
+	ErrNotEnoughRight        = NewError(52)
+	ErrListenerNotFound      = NewError(53)
+	ErrListenerAlreadyExists = NewError(54)
+	ErrHubAlreadyExists      = NewError(57)
+	ErrLinkAlreadyExists     = NewError(59)
+	ErrLinkIsOffline         = NewError(61)
+	ErrGroupNotFound         = NewError(65)
+	ErrUserAlreadyExists     = NewError(66)
+	ErrGroupAlreadyExists    = NewError(67)
+	ErrObjectExists          = NewError(112)
+	ErrBadCommandOrParam     = NewError(117)
+)
+
+// Sentinel errors for failures which are not SoftEther error codes.
+var (
+	ErrVpncmdNotFound = NewKindError(KindNotFound, "", nil)
+	ErrTimeout        = NewKindError(KindTimeout, "", nil)
+	ErrSignal         = NewKindError(KindSignal, "", nil)
+	ErrParse          = NewKindError(KindParse, "", nil)
+	ErrExec           = NewKindError(KindExec, "", nil)
+	ErrCanceled       = NewKindError(KindCanceled, "", nil)
+	ErrInvalidAddress = NewKindError(KindInvalidAddress, "", nil)
+	ErrCertMismatch   = NewKindError(KindCertMismatch, "", nil)
+)
+
+// Error is returned when a vpncmd command fails. For KindErrorCode errors, Code
+// holds the SoftEther error code vpncmd exited with.
+type Error struct {
+	Kind    Kind   // Why the command failed
+	Code    int    // SoftEther error code, e.g. 8
+	Name    string // Symbolic name of Code, e.g. "ERR_HUB_NOT_FOUND"
+	Message string // Human-readable message, e.g. "hub not found"
+	Command string // vpncmd command which failed, e.g. "SessionList"
+	Stderr  string // Standard error output of vpncmd
+	Err     error  // Underlying error, if any
+}
+
+func (e *Error) Error() string {
+	msg := "softether: "
+	if e.Command != "" {
+		msg += e.Command + ": "
+	}
+	msg += e.Message
+	if e.Kind == KindErrorCode {
+		msg += " (" + e.Name + ")"
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error of the same Kind and, for KindErrorCode,
+// the same Code, so that the sentinel errors match the errors returned by SoftEther methods.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok || t.Kind != e.Kind {
+		return false
+	}
+	return e.Kind != KindErrorCode || t.Code == e.Code
+}
+
+// NewError returns an *Error for the SoftEther error code errno, with its name and
+// a message derived from it.
+func NewError(errno int) *Error {
+	name := Strerror(errno)
+	if name == "" {
+		name = fmt.Sprintf("ERR_%d", errno)
+	}
+	message := strings.ToLower(strings.Replace(strings.TrimPrefix(name, "ERR_"), "_", " ", -1))
+
+	return &Error{
+		Kind:    KindErrorCode,
+		Code:    errno,
+		Name:    name,
+		Message: message,
+	}
+}
+
+// NewKindError returns an *Error of kind for command, wrapping err.
+func NewKindError(kind Kind, command string, err error) *Error {
+	return &Error{
+		Kind:    kind,
+		Message: kind.String(),
+		Command: command,
+		Err:     err,
+	}
+}
+
 // Strerror Given an error number, returns an error string
 func Strerror(errno int) string {
-	return errors[errno]
+	return errorNames[errno]
 }
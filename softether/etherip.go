@@ -0,0 +1,75 @@
+package softether
+
+import "context"
+
+// EtherIPClient is a device registered to connect to a Virtual Hub with EtherIP / L2TPv3 over IPsec,
+// as listed by ListEtherIPClients.
+type EtherIPClient struct {
+	ID       string // ISAKMP phase 1 ID the device sends; "*" matches any device
+	Hub      string
+	Username string
+}
+
+// parseEtherIPClient converts a row of EtherIpClientList.
+func parseEtherIPClient(m map[string]string) EtherIPClient {
+	return EtherIPClient{
+		ID:       parseString(m["ISAKMP Phase 1 ID"]),
+		Hub:      parseString(m["Virtual Hub Name"]),
+		Username: parseString(m["User Name"]),
+	}
+}
+
+// ListEtherIPClients executes vpncmd and gets the EtherIP / L2TPv3 devices registered on the SoftEther server.
+func (s SoftEther) ListEtherIPClients(ctx context.Context) (clientList []EtherIPClient, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /cmd EtherIpClientList
+	cmd := s.command("EtherIpClientList")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	rows, err := parseTable(cmd, cmdOutput, "ISAKMP Phase 1 ID")
+	for _, row := range rows {
+		clientList = append(clientList, parseEtherIPClient(row))
+	}
+
+	return
+}
+
+// AddEtherIPClient executes vpncmd and registers an EtherIP / L2TPv3 device with the ISAKMP
+// phase 1 ID id, which connects to the Virtual Hub hub as the User username with password.
+func (s SoftEther) AddEtherIPClient(ctx context.Context, id, hub, username, password string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /in:[SCRIPT], SCRIPT: EtherIpClientAdd [ID] /HUB:[HUB] /USERNAME:[USERNAME] /PASSWORD:[PASSWORD]
+	cmd := s.command(
+		"EtherIpClientAdd",
+		id,
+		"/HUB:"+hub,
+		"/USERNAME:"+username,
+		"/PASSWORD:"+password,
+	)
+	cmd.Secrets = []int{3}
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// DeleteEtherIPClient executes vpncmd and removes the EtherIP / L2TPv3 device with the ISAKMP phase 1 ID id.
+func (s SoftEther) DeleteEtherIPClient(ctx context.Context, id string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /cmd EtherIpClientDelete [ID]
+	cmd := s.command(
+		"EtherIpClientDelete",
+		id,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
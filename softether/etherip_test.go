@@ -0,0 +1,66 @@
+package softether_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+)
+
+func TestEtherIPCommands(t *testing.T) {
+	runCommandTests(t, []commandTest{
+		{
+			name: "ListEtherIPClients",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.ListEtherIPClients(ctx)
+				return err
+			},
+			want:  [][]string{{"EtherIpClientList"}},
+			errno: 52, // ERR_NOT_ENOUGH_RIGHT
+		},
+		{
+			name: "AddEtherIPClient",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.AddEtherIPClient(ctx, "branch-router-2", "subspace", "router2", "router-pass")
+			},
+			want:    [][]string{{"EtherIpClientAdd", "branch-router-2", "/HUB:subspace", "/USERNAME:router2", "/PASSWORD:router-pass"}},
+			secrets: []string{"router-pass"},
+			errno:   38, // ERR_INVALID_PARAMETER
+		},
+		{
+			name: "AddEtherIPClient any device",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.AddEtherIPClient(ctx, "*", "tenant", "anyrouter", "router-pass")
+			},
+			want:    [][]string{{"EtherIpClientAdd", "*", "/HUB:tenant", "/USERNAME:anyrouter", "/PASSWORD:router-pass"}},
+			secrets: []string{"router-pass"},
+			errno:   38, // ERR_INVALID_PARAMETER
+		},
+		{
+			name: "DeleteEtherIPClient",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.DeleteEtherIPClient(ctx, "branch-router-1")
+			},
+			want:  [][]string{{"EtherIpClientDelete", "branch-router-1"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+	})
+}
+
+func TestListEtherIPClients(t *testing.T) {
+	s, _ := newServer()
+
+	clientList, err := s.ListEtherIPClients(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []softether.EtherIPClient{
+		{ID: "branch-router-1", Hub: "subspace", Username: "router1"},
+		{ID: "*", Hub: "subspace", Username: "anyrouter"},
+	}
+	if !reflect.DeepEqual(clientList, want) {
+		t.Errorf("clientList = %+v, want %+v", clientList, want)
+	}
+}
@@ -0,0 +1,87 @@
+package softether
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Fingerprint returns the SHA-256 fingerprint of cert as lowercase hex.
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeFingerprint converts fingerprints such as "AB:CD:..." to the form Fingerprint returns.
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(fingerprint), ":", "", -1))
+}
+
+// MatchFingerprint reports whether cert has the SHA-256 fingerprint fingerprint, given in
+// the form Fingerprint returns or as colon separated hex such as "91:CB:9C:70:...".
+func MatchFingerprint(cert *x509.Certificate, fingerprint string) bool {
+	return Fingerprint(cert) == normalizeFingerprint(fingerprint)
+}
+
+// GetServerFingerprint connects to the SoftEther server and returns the SHA-256
+// fingerprint of the certificate it presents, as returned by Fingerprint. Use it to
+// obtain the value for SoftEther.Fingerprint or jsonrpc.Client.Fingerprint on first
+// contact. It does not run vpncmd.
+func (s SoftEther) GetServerFingerprint(ctx context.Context) (fingerprint string, err error) {
+	cert, err := s.fetchServerCert(ctx, "")
+	if err != nil {
+		return
+	}
+	return Fingerprint(cert), nil
+}
+
+// fetchServerCert performs a TLS handshake with the server, without verifying its
+// certificate, and returns the certificate it presents. Failures are reported for command.
+func (s SoftEther) fetchServerCert(ctx context.Context, command string) (cert *x509.Certificate, err error) {
+	address, err := s.Address()
+	if err != nil {
+		return nil, NewKindError(KindInvalidAddress, command, err)
+	}
+
+	dialer := tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: s.Timeout},
+		Config:    &tls.Config{InsecureSkipVerify: true}, // The certificate is checked against the fingerprint
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		connectError := NewError(1) // ERR_CONNECT_FAILED
+		connectError.Command = command
+		connectError.Err = err
+		return nil, connectError
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		connectError := NewError(1) // ERR_CONNECT_FAILED
+		connectError.Command = command
+		connectError.Err = fmt.Errorf("no server certificate")
+		return nil, connectError
+	}
+
+	return certs[0], nil
+}
+
+// verifyFingerprint checks that the server presents a certificate matching
+// s.Fingerprint before command is run, and fails with ErrCertMismatch otherwise.
+func (s SoftEther) verifyFingerprint(ctx context.Context, command string) error {
+	cert, err := s.fetchServerCert(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	if !MatchFingerprint(cert, s.Fingerprint) {
+		return NewKindError(KindCertMismatch, command, fmt.Errorf("got fingerprint %s, want %s", Fingerprint(cert), normalizeFingerprint(s.Fingerprint)))
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package softether_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether/softethertest"
+)
+
+// newTLSServer returns a SoftEther for a local TLS server, which runs its commands
+// through a fake vpncmd, and the fingerprint of the certificate the server presents.
+func newTLSServer(t *testing.T) (softether.SoftEther, *softethertest.Runner, string) {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.Config.ErrorLog = log.New(io.Discard, "", 0) // The fingerprint probe hangs up during the handshake
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	portNumber, _ := strconv.Atoi(port)
+
+	runner := softethertest.NewRunner()
+	s := softether.SoftEther{IP: host, Port: portNumber, Password: "subspace", Hub: "subspace", Runner: runner}
+	return s, runner, softether.Fingerprint(server.Certificate())
+}
+
+// colonFingerprint converts a fingerprint to the "AB:CD:..." form.
+func colonFingerprint(fingerprint string) string {
+	var pairs []string
+	for i := 0; i < len(fingerprint); i += 2 {
+		pairs = append(pairs, strings.ToUpper(fingerprint[i:i+2]))
+	}
+	return strings.Join(pairs, ":")
+}
+
+func TestGetServerFingerprint(t *testing.T) {
+	s, runner, want := newTLSServer(t)
+
+	fingerprint, err := s.GetServerFingerprint(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if fingerprint != want {
+		t.Errorf("fingerprint = %s, want %s", fingerprint, want)
+	}
+	if len(runner.Commands()) != 0 {
+		t.Errorf("commands = %v, want none", runner.Commands())
+	}
+
+	// Nothing listens on the port any more
+	s.Port = 1
+	_, err = s.GetServerFingerprint(context.Background())
+	checkError(t, err, 1)
+}
+
+func TestFingerprintPinning(t *testing.T) {
+	tests := []struct {
+		name        string
+		fingerprint func(actual string) string // Fingerprint to pin, given the actual one
+		mismatch    bool
+	}{
+		{name: "match", fingerprint: func(actual string) string { return actual }},
+		{name: "colon separated", fingerprint: colonFingerprint},
+		{name: "mismatch", fingerprint: func(string) string { return strings.Repeat("00", 32) }, mismatch: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, runner, actual := newTLSServer(t)
+			s.Fingerprint = test.fingerprint(actual)
+
+			_, err := s.GetUserList(context.Background())
+			if !test.mismatch {
+				if err != nil {
+					t.Fatalf("err = %v", err)
+				}
+				if commands := runner.Commands(); len(commands) != 1 || commands[0].Name != "UserList" {
+					t.Errorf("commands = %v, want UserList", commands)
+				}
+				return
+			}
+
+			checkKind(t, err, softether.KindCertMismatch)
+			if !errors.Is(err, softether.ErrCertMismatch) || !strings.Contains(err.Error(), actual) {
+				t.Errorf("err = %v, want ErrCertMismatch naming %s", err, actual)
+			}
+			if len(runner.Commands()) != 0 {
+				t.Errorf("vpncmd ran %v despite the mismatch", runner.Commands())
+			}
+		})
+	}
+}
+
+func TestMatchFingerprint(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	cert := server.Certificate()
+	fingerprint := softether.Fingerprint(cert)
+
+	if len(fingerprint) != 64 || strings.ToLower(fingerprint) != fingerprint {
+		t.Errorf("Fingerprint = %q, want 64 lowercase hex digits", fingerprint)
+	}
+	for _, value := range []string{fingerprint, strings.ToUpper(fingerprint), colonFingerprint(fingerprint), " " + fingerprint + "\n"} {
+		if !softether.MatchFingerprint(cert, value) {
+			t.Errorf("MatchFingerprint(%q) = false", value)
+		}
+	}
+	for _, value := range []string{"", fingerprint[:62], strings.Repeat("00", 32)} {
+		if softether.MatchFingerprint(cert, value) {
+			t.Errorf("MatchFingerprint(%q) = true", value)
+		}
+	}
+}
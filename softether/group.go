@@ -0,0 +1,184 @@
+package softether
+
+import "context"
+
+// Group is a group of users in a Virtual Hub. ListGroups only fills in the fields
+// shown by GroupList; GetGroup fills in all of them but NumberOfUsers, which it
+// derives from Members.
+type Group struct {
+	Name          string
+	FullName      string
+	Description   string
+	NumberOfUsers int
+	Members       []string // User names; only filled in by GetGroup
+}
+
+// parseGroup converts a row of GroupList or the output table of GroupGet.
+func parseGroup(m map[string]string) Group {
+	return Group{
+		Name:          parseString(m["Group Name"]),
+		FullName:      parseString(m["Full Name"]),
+		Description:   parseString(m["Description"]),
+		NumberOfUsers: parseInt(m["Num Users"]),
+	}
+}
+
+// groupMembersCaption is the line GroupGet prints before the names of the members.
+const groupMembersCaption = "This is a list of user names of users who are assigned to this group."
+
+// parseGroupMembers reads the member list GroupGet prints after the group table and
+// its policy: groupMembersCaption followed by one indented user name per line.
+func parseGroupMembers(cmd Command, output []byte) (members []string, err error) {
+	records, err := readCSV(cmd, output)
+	if err != nil {
+		return
+	}
+
+	inList := false
+	for _, record := range records {
+		if len(record) != 1 {
+			inList = false // Not part of the member list
+			continue
+		}
+		if record[0] == groupMembersCaption {
+			inList = true
+			continue
+		}
+		if inList && record[0] != "" {
+			members = append(members, record[0])
+		}
+	}
+
+	return
+}
+
+// ListGroups executes vpncmd and gets the list of groups in a specific Hub.
+func (s SoftEther) ListGroups(ctx context.Context) (groupList []Group, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd GroupList
+	cmd := s.hubCommand("GroupList")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	rows, err := parseTable(cmd, cmdOutput, "Group Name")
+	for _, row := range rows {
+		groupList = append(groupList, parseGroup(row))
+	}
+
+	return
+}
+
+// CreateGroup executes vpncmd and creates a group in a specific Hub.
+func (s SoftEther) CreateGroup(ctx context.Context, name, fullName, description string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd GroupCreate [NAME] /REALNAME:[FULL_NAME] /NOTE:[DESCRIPTION]
+	cmd := s.hubCommand(
+		"GroupCreate",
+		name,
+		"/REALNAME:"+fullName,
+		"/NOTE:"+description,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// GetGroup executes vpncmd and gets the details and members of a specific group in a specific Hub.
+func (s SoftEther) GetGroup(ctx context.Context, name string) (group Group, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd GroupGet [NAME]
+	cmd := s.hubCommand(
+		"GroupGet",
+		name,
+	)
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	groupInfo, err := parseKeyValue(cmd, cmdOutput)
+	if err != nil {
+		return
+	}
+	members, err := parseGroupMembers(cmd, cmdOutput)
+	if err != nil {
+		return
+	}
+
+	group = parseGroup(groupInfo)
+	group.Members = members
+	group.NumberOfUsers = len(members)
+	return
+}
+
+// SetGroup executes vpncmd and updates the full name and description of a specific group in a specific Hub.
+func (s SoftEther) SetGroup(ctx context.Context, name, fullName, description string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd GroupSet [NAME] /REALNAME:[FULL_NAME] /NOTE:[DESCRIPTION]
+	cmd := s.hubCommand(
+		"GroupSet",
+		name,
+		"/REALNAME:"+fullName,
+		"/NOTE:"+description,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// DeleteGroup executes vpncmd and deletes a specific group in a specific Hub.
+// Its members are kept, but no longer belong to any group.
+func (s SoftEther) DeleteGroup(ctx context.Context, name string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd GroupDelete [NAME]
+	cmd := s.hubCommand(
+		"GroupDelete",
+		name,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// GroupJoin executes vpncmd and adds a User to a specific group in a specific Hub.
+// A user belongs to at most one group; joining moves it out of its current one.
+func (s SoftEther) GroupJoin(ctx context.Context, name, username string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd GroupJoin [NAME] /USERNAME:[USERNAME]
+	cmd := s.hubCommand(
+		"GroupJoin",
+		name,
+		"/USERNAME:"+username,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// GroupUnjoin executes vpncmd and removes a User from its group in a specific Hub.
+func (s SoftEther) GroupUnjoin(ctx context.Context, username string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd GroupUnjoin [USERNAME]
+	cmd := s.hubCommand(
+		"GroupUnjoin",
+		username,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
@@ -0,0 +1,148 @@
+package softether_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether/softethertest"
+)
+
+func TestGroupCommands(t *testing.T) {
+	runCommandTests(t, []commandTest{
+		{
+			name: "ListGroups",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.ListGroups(ctx)
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"GroupList"}},
+			errno: 8, // ERR_HUB_NOT_FOUND
+		},
+		{
+			name: "CreateGroup",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.CreateGroup(ctx, "guests", "Guests", "Visitors")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"GroupCreate", "guests", "/REALNAME:Guests", "/NOTE:Visitors"}},
+			errno: 67, // ERR_GROUP_ALREADY_EXISTS
+		},
+		{
+			name: "GetGroup",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.GetGroup(ctx, "admins")
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"GroupGet", "admins"}},
+			errno: 65, // ERR_GROUP_NOT_FOUND
+		},
+		{
+			name: "SetGroup",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetGroup(ctx, "admins", "Admins", "")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"GroupSet", "admins", "/REALNAME:Admins", "/NOTE:"}},
+			errno: 65, // ERR_GROUP_NOT_FOUND
+		},
+		{
+			name: "DeleteGroup",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.DeleteGroup(ctx, "admins")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"GroupDelete", "admins"}},
+			errno: 65, // ERR_GROUP_NOT_FOUND
+		},
+		{
+			name: "GroupJoin",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.GroupJoin(ctx, "admins", "2")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"GroupJoin", "admins", "/USERNAME:2"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "GroupUnjoin",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.GroupUnjoin(ctx, "2")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"GroupUnjoin", "2"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+	})
+}
+
+func TestListGroups(t *testing.T) {
+	s, _ := newServer()
+
+	groupList, err := s.ListGroups(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []softether.Group{
+		{Name: "admins", FullName: "Administrators", Description: "Staff, with full access", NumberOfUsers: 1},
+		{Name: "guests", FullName: "Guests"},
+	}
+	if !reflect.DeepEqual(groupList, want) {
+		t.Errorf("groupList = %+v, want %+v", groupList, want)
+	}
+}
+
+func TestGetGroup(t *testing.T) {
+	tests := []struct {
+		name   string
+		stdout string
+		want   softether.Group
+	}{
+		{
+			name: "fixture",
+			want: softether.Group{
+				Name:          "admins",
+				FullName:      "Administrators",
+				Description:   "Staff, with full access",
+				NumberOfUsers: 1,
+				Members:       []string{"1"},
+			},
+		},
+		{
+			name: "several members",
+			stdout: "Item,Value\nGroup Name,staff\nFull Name,-\nDescription,-\n\n" +
+				"This is a list of user names of users who are assigned to this group.\n 1\n 2\n alice\n\n",
+			want: softether.Group{
+				Name:          "staff",
+				NumberOfUsers: 3,
+				Members:       []string{"1", "2", "alice"},
+			},
+		},
+		{
+			name:   "no members",
+			stdout: "Item,Value\nGroup Name,empty\nFull Name,Empty\nDescription,-\n",
+			want:   softether.Group{Name: "empty", FullName: "Empty"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, runner := newServer()
+			if test.stdout != "" {
+				runner.Handle("GroupGet", softethertest.Response{Stdout: test.stdout})
+			}
+
+			group, err := s.GetGroup(context.Background(), test.want.Name)
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if !reflect.DeepEqual(group, test.want) {
+				t.Errorf("group = %+v, want %+v", group, test.want)
+			}
+		})
+	}
+}
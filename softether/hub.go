@@ -0,0 +1,173 @@
+package softether
+
+import (
+	"context"
+	"time"
+)
+
+// HubType is the type of a Virtual Hub, as shown by vpncmd.
+type HubType string
+
+// Virtual Hub types. Static and dynamic Hubs only exist on cluster controllers.
+const (
+	HubStandalone HubType = "Standalone"
+	HubStatic     HubType = "Static"
+	HubDynamic    HubType = "Dynamic"
+)
+
+// Hub is a Virtual Hub. ListHubs only fills in the fields shown by HubList;
+// GetHubStatus fills in all of them.
+type Hub struct {
+	Name                   string
+	Online                 bool
+	Type                   HubType
+	SecureNAT              bool
+	NumberOfSessions       int
+	NumberOfClientSessions int
+	NumberOfBridgeSessions int
+	NumberOfAccessLists    int
+	NumberOfUsers          int
+	NumberOfGroups         int
+	NumberOfMACTables      int
+	NumberOfIPTables       int
+	NumberOfLogins         int
+	LastLogin              time.Time
+	LastCommunication      time.Time
+	CreatedAt              time.Time
+	TransferBytes          int64
+	TransferPackets        int64
+	Traffic
+}
+
+// parseHub converts a row of HubList or the output table of StatusGet.
+func parseHub(m map[string]string) Hub {
+	return Hub{
+		Name:                   parseString(m["Virtual Hub Name"]),
+		Online:                 parseString(m["Status"]) == "Online",
+		Type:                   HubType(parseString(m["Type"])),
+		SecureNAT:              parseBool(m["SecureNAT"]),
+		NumberOfSessions:       parseInt(m["Sessions"]),
+		NumberOfClientSessions: parseInt(m["Sessions (Client)"]),
+		NumberOfBridgeSessions: parseInt(m["Sessions (Bridge)"]),
+		NumberOfAccessLists:    parseInt(m["Access Lists"]),
+		NumberOfUsers:          parseInt(m["Users"]),
+		NumberOfGroups:         parseInt(m["Groups"]),
+		NumberOfMACTables:      parseInt(m["MAC Tables"]),
+		NumberOfIPTables:       parseInt(m["IP Tables"]),
+		NumberOfLogins:         parseInt(m["Num Logins"]),
+		LastLogin:              parseTime(m["Last Login"]),
+		LastCommunication:      parseTime(m["Last Communication"]),
+		CreatedAt:              parseTime(m["Created at"]),
+		TransferBytes:          parseCount(m["Transfer Bytes"]),
+		TransferPackets:        parseCount(m["Transfer Packets"]),
+		Traffic:                parseTraffic(m),
+	}
+}
+
+// ListHubs executes vpncmd and gets the list of Virtual Hubs on the SoftEther server.
+func (s SoftEther) ListHubs(ctx context.Context) (hubList []Hub, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /cmd HubList
+	cmd := s.command("HubList")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	rows, err := parseTable(cmd, cmdOutput, "Virtual Hub Name")
+	for _, row := range rows {
+		hubList = append(hubList, parseHub(row))
+	}
+
+	return
+}
+
+// CreateHub executes vpncmd and creates a Virtual Hub with an administrator password.
+// Static and dynamic Hubs can only be created on a cluster controller.
+func (s SoftEther) CreateHub(ctx context.Context, name string, password string, hubType HubType) (err error) {
+
+	// Each Hub type has its own command
+	command := "HubCreate"
+	switch hubType {
+	case HubStatic:
+		command = "HubCreateStatic"
+	case HubDynamic:
+		command = "HubCreateDynamic"
+	}
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /in:[SCRIPT], SCRIPT: HubCreate|HubCreateStatic|HubCreateDynamic [NAME] /PASSWORD:[PASSWORD]
+	cmd := s.command(
+		command,
+		name,
+		"/PASSWORD:"+password,
+	)
+	cmd.Secrets = []int{1}
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// DeleteHub executes vpncmd and deletes a Virtual Hub, disconnecting all of its sessions.
+func (s SoftEther) DeleteHub(ctx context.Context, name string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /cmd HubDelete [NAME]
+	cmd := s.command(
+		"HubDelete",
+		name,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// SetHubOnline executes vpncmd and puts a Virtual Hub online.
+func (s SoftEther) SetHubOnline(ctx context.Context, name string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[NAME] /csv /cmd Online
+	cmd := s.hubCommandFor(name, "Online")
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// SetHubOffline executes vpncmd and takes a Virtual Hub offline, disconnecting all of its sessions.
+func (s SoftEther) SetHubOffline(ctx context.Context, name string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[NAME] /csv /cmd Offline
+	cmd := s.hubCommandFor(name, "Offline")
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// GetHubStatus executes vpncmd and gets the status of a Virtual Hub.
+func (s SoftEther) GetHubStatus(ctx context.Context, name string) (hub Hub, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[NAME] /csv /cmd StatusGet
+	cmd := s.hubCommandFor(name, "StatusGet")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	hubInfo, err := parseKeyValue(cmd, cmdOutput)
+	if err != nil {
+		return
+	}
+
+	hub = parseHub(hubInfo)
+	return
+}
@@ -0,0 +1,158 @@
+package softether_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+)
+
+func TestHubCommands(t *testing.T) {
+	runCommandTests(t, []commandTest{
+		{
+			name: "ListHubs",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.ListHubs(ctx)
+				return err
+			},
+			want:  [][]string{{"HubList"}},
+			errno: 52, // ERR_NOT_ENOUGH_RIGHT
+		},
+		{
+			name: "CreateHub",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.CreateHub(ctx, "tenant", "hub-pass", softether.HubStandalone)
+			},
+			want:    [][]string{{"HubCreate", "tenant", "/PASSWORD:hub-pass"}},
+			secrets: []string{"hub-pass"},
+			errno:   57, // ERR_HUB_ALREADY_EXISTS
+		},
+		{
+			name: "CreateHub static",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.CreateHub(ctx, "tenant", "hub-pass", softether.HubStatic)
+			},
+			want:    [][]string{{"HubCreateStatic", "tenant", "/PASSWORD:hub-pass"}},
+			secrets: []string{"hub-pass"},
+			errno:   46, // ERR_NOT_FARM_CONTROLLER
+		},
+		{
+			name: "CreateHub dynamic",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.CreateHub(ctx, "tenant", "hub-pass", softether.HubDynamic)
+			},
+			want:    [][]string{{"HubCreateDynamic", "tenant", "/PASSWORD:hub-pass"}},
+			secrets: []string{"hub-pass"},
+			errno:   46, // ERR_NOT_FARM_CONTROLLER
+		},
+		{
+			name: "DeleteHub",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.DeleteHub(ctx, "tenant")
+			},
+			want:  [][]string{{"HubDelete", "tenant"}},
+			errno: 8, // ERR_HUB_NOT_FOUND
+		},
+		{
+			name: "SetHubOnline",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetHubOnline(ctx, "tenant")
+			},
+			hub:   "tenant",
+			want:  [][]string{{"Online"}},
+			errno: 75, // ERR_ALREADY_ONLINE
+		},
+		{
+			name: "SetHubOffline",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetHubOffline(ctx, "tenant")
+			},
+			hub:   "tenant",
+			want:  [][]string{{"Offline"}},
+			errno: 76, // ERR_OFFLINE
+		},
+		{
+			name: "GetHubStatus",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.GetHubStatus(ctx, "tenant")
+				return err
+			},
+			hub:   "tenant",
+			want:  [][]string{{"StatusGet"}},
+			errno: 8, // ERR_HUB_NOT_FOUND
+		},
+	})
+}
+
+func TestListHubs(t *testing.T) {
+	s, _ := newServer()
+
+	hubList, err := s.ListHubs(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []softether.Hub{
+		{
+			Name:              "subspace",
+			Online:            true,
+			Type:              softether.HubStandalone,
+			NumberOfSessions:  2,
+			NumberOfUsers:     2,
+			NumberOfMACTables: 3,
+			NumberOfIPTables:  4,
+			NumberOfLogins:    12,
+			LastLogin:         date("2017-04-19 02:05:16"),
+			LastCommunication: date("2017-04-20 10:11:12"),
+			TransferBytes:     5969441,
+			TransferPackets:   35785,
+		},
+		{
+			Name:              "tenant",
+			Type:              softether.HubStandalone,
+			LastCommunication: date("2017-04-18 09:00:00"),
+		},
+	}
+	if !reflect.DeepEqual(hubList, want) {
+		t.Errorf("hubList = %+v, want %+v", hubList, want)
+	}
+}
+
+func TestGetHubStatus(t *testing.T) {
+	s, _ := newServer()
+
+	hub, err := s.GetHubStatus(context.Background(), "subspace")
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := softether.Hub{
+		Name:                   "subspace",
+		Online:                 true,
+		Type:                   softether.HubStandalone,
+		SecureNAT:              true,
+		NumberOfSessions:       2,
+		NumberOfClientSessions: 1,
+		NumberOfUsers:          2,
+		NumberOfMACTables:      3,
+		NumberOfIPTables:       4,
+		NumberOfLogins:         12,
+		LastLogin:              date("2017-04-19 02:05:16"),
+		LastCommunication:      date("2017-04-20 10:11:12"),
+		CreatedAt:              date("2017-04-01 00:00:00"),
+		Traffic: softether.Traffic{
+			OutgoingUnicastPackets:   12340,
+			OutgoingUnicastBytes:     4724634,
+			OutgoingBroadcastPackets: 5,
+			OutgoingBroadcastBytes:   10240,
+			IncomingUnicastPackets:   2345,
+			IncomingUnicastBytes:     1214087,
+			IncomingBroadcastPackets: 6,
+			IncomingBroadcastBytes:   20480,
+		},
+	}
+	if !reflect.DeepEqual(hub, want) {
+		t.Errorf("hub = %+v, want %+v", hub, want)
+	}
+}
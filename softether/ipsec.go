@@ -0,0 +1,85 @@
+package softether
+
+import "context"
+
+// IPsecConfig is the IPsec / L2TP configuration of the SoftEther server.
+type IPsecConfig struct {
+	L2TP         bool   // L2TP over IPsec
+	L2TPRaw      bool   // Raw L2TP without encryption
+	EtherIP      bool   // EtherIP / L2TPv3 over IPsec
+	PreSharedKey string // IPsec pre-shared key, at most 9 characters for some clients
+	DefaultHub   string // Virtual Hub of users who do not specify one in their user name
+}
+
+// parseIPsecConfig converts the output table of IPsecGet. It fails if an item is missing,
+// so that SetIPsecConfig never writes back a setting it did not read.
+func parseIPsecConfig(cmd Command, m map[string]string) (config IPsecConfig, err error) {
+	item := func(label string) string {
+		value, ok := m[label]
+		if !ok && err == nil {
+			err = parseFailure(cmd, "no %q in output", label)
+		}
+		return value
+	}
+
+	config = IPsecConfig{
+		L2TP:         parseBool(item("L2TP over IPsec Server Function Enabled")),
+		L2TPRaw:      parseBool(item("Raw L2TP Server Function Enabled")),
+		EtherIP:      parseBool(item("EtherIP / L2TPv3 over IPsec Server Function Enabled")),
+		PreSharedKey: item("IPsec Pre-Shared Key String"),
+		DefaultHub:   parseString(item("Name of Default Virtual Hub")),
+	}
+	if err != nil {
+		return IPsecConfig{}, err
+	}
+	return
+}
+
+// GetIPsecConfig executes vpncmd and gets the IPsec / L2TP configuration of the SoftEther server.
+func (s SoftEther) GetIPsecConfig(ctx context.Context) (config IPsecConfig, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /cmd IPsecGet
+	cmd := s.command("IPsecGet")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	configMap, err := parseKeyValue(cmd, cmdOutput)
+	if err != nil {
+		return
+	}
+
+	return parseIPsecConfig(cmd, configMap)
+}
+
+// SetIPsecConfig executes vpncmd and updates the IPsec / L2TP configuration of the SoftEther
+// server. It reads the current configuration, lets update change it, and writes it back,
+// so that settings update does not touch are preserved.
+func (s SoftEther) SetIPsecConfig(ctx context.Context, update func(config *IPsecConfig)) (err error) {
+	config, err := s.GetIPsecConfig(ctx)
+	if err != nil {
+		return
+	}
+	update(&config)
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /in:[SCRIPT], SCRIPT: IPsecEnable /L2TP:[yes|no] /L2TPRAW:[yes|no] /ETHERIP:[yes|no] /PSK:[PRE_SHARED_KEY] /DEFAULTHUB:[DEFAULT_HUB]
+	cmd := s.command(
+		"IPsecEnable",
+		"/L2TP:"+formatBool(config.L2TP),
+		"/L2TPRAW:"+formatBool(config.L2TPRaw),
+		"/ETHERIP:"+formatBool(config.EtherIP),
+		"/PSK:"+config.PreSharedKey,
+		"/DEFAULTHUB:"+config.DefaultHub,
+	)
+	cmd.Secrets = []int{3}
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
@@ -0,0 +1,139 @@
+package softether_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether/softethertest"
+)
+
+func TestIPsecCommands(t *testing.T) {
+	runCommandTests(t, []commandTest{
+		{
+			name: "GetIPsecConfig",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.GetIPsecConfig(ctx)
+				return err
+			},
+			want:  [][]string{{"IPsecGet"}},
+			errno: 52, // ERR_NOT_ENOUGH_RIGHT
+		},
+		{
+			name: "SetIPsecConfig",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetIPsecConfig(ctx, func(config *softether.IPsecConfig) {
+					config.L2TPRaw = true
+					config.EtherIP = false
+					config.PreSharedKey = "psk-secret"
+					config.DefaultHub = "tenant"
+				})
+			},
+			want: [][]string{
+				{"IPsecGet"},
+				{"IPsecEnable", "/L2TP:yes", "/L2TPRAW:yes", "/ETHERIP:no", "/PSK:psk-secret", "/DEFAULTHUB:tenant"},
+			},
+			secrets: []string{"psk-secret"},
+			errno:   52, // ERR_NOT_ENOUGH_RIGHT
+		},
+		{
+			name: "SetIPsecConfig unchanged",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetIPsecConfig(ctx, func(config *softether.IPsecConfig) {
+					config.PreSharedKey = "psk-secret"
+				})
+			},
+			want: [][]string{
+				{"IPsecGet"},
+				{"IPsecEnable", "/L2TP:yes", "/L2TPRAW:no", "/ETHERIP:yes", "/PSK:psk-secret", "/DEFAULTHUB:subspace"},
+			},
+			secrets: []string{"psk-secret"},
+			errno:   52, // ERR_NOT_ENOUGH_RIGHT
+		},
+	})
+}
+
+func TestGetIPsecConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		stdout string
+		want   softether.IPsecConfig
+	}{
+		{
+			name: "fixture",
+			want: softether.IPsecConfig{L2TP: true, EtherIP: true, PreSharedKey: "vpn", DefaultHub: "subspace"},
+		},
+		{
+			name: "disabled",
+			stdout: "Item,Value\nL2TP over IPsec Server Function Enabled,No\nRaw L2TP Server Function Enabled,No\n" +
+				"EtherIP / L2TPv3 over IPsec Server Function Enabled,No\nIPsec Pre-Shared Key String,\"k,e y\"\n" +
+				"Name of Default Virtual Hub,-\n",
+			want: softether.IPsecConfig{PreSharedKey: "k,e y"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, runner := newServer()
+			if test.stdout != "" {
+				runner.Handle("IPsecGet", softethertest.Response{Stdout: test.stdout})
+			}
+
+			config, err := s.GetIPsecConfig(context.Background())
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if config != test.want {
+				t.Errorf("config = %+v, want %+v", config, test.want)
+			}
+		})
+	}
+}
+
+func TestSetIPsecConfigFailure(t *testing.T) {
+	s, runner := newServer()
+	runner.Fail("IPsecGet", 52)
+
+	called := false
+	err := s.SetIPsecConfig(context.Background(), func(*softether.IPsecConfig) { called = true })
+	checkError(t, err, 52)
+	if called {
+		t.Error("update was called without the current configuration")
+	}
+	checkCommands(t, runner, "", []string{"IPsecGet"})
+}
+
+func TestSetIPsecConfigMissingItem(t *testing.T) {
+	// Without the default Hub, writing the configuration back would clear it
+	s, runner := newServer()
+	runner.Handle("IPsecGet", softethertest.Response{Stdout: "Item,Value\nL2TP over IPsec Server Function Enabled,Yes\n" +
+		"Raw L2TP Server Function Enabled,No\nEtherIP / L2TPv3 over IPsec Server Function Enabled,Yes\n" +
+		"IPsec Pre-Shared Key String,vpn\n"})
+
+	_, err := s.GetIPsecConfig(context.Background())
+	checkKind(t, err, softether.KindParse)
+
+	s, runner = newServer()
+	runner.Handle("IPsecGet", softethertest.Response{Stdout: "Item,Value\nIPsec Pre-Shared Key String,vpn\n"})
+	called := false
+	err = s.SetIPsecConfig(context.Background(), func(*softether.IPsecConfig) { called = true })
+	checkKind(t, err, softether.KindParse)
+	if called {
+		t.Error("update was called without the current configuration")
+	}
+	checkCommands(t, runner, "", []string{"IPsecGet"})
+}
+
+func TestSetIPsecConfigRedacted(t *testing.T) {
+	// The pre-shared key "vpn" of the fixture is redacted in its value only
+	s, runner := newServer()
+	if err := s.SetIPsecConfig(context.Background(), func(*softether.IPsecConfig) {}); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	line := runner.Commands()[1].String()
+	if !strings.HasPrefix(line, "vpncmd ") || !strings.Contains(line, " /PSK:******** ") || strings.Contains(line, "/PSK:vpn") {
+		t.Errorf("String() = %s", line)
+	}
+}
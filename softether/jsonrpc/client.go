@@ -0,0 +1,361 @@
+// Package jsonrpc implements the softether.API on top of the JSON-RPC admin API
+// which SoftEther VPN Server 4.29 and later serves over HTTPS at /api/, removing
+// the dependency on the vpncmd binary.
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+)
+
+// Client is a JSON-RPC backend for a Virtual Hub of a SoftEther server.
+//
+// SoftEther servers usually present a self-signed certificate. Either pin it with
+// Fingerprint, or give HTTPClient a TLS configuration which trusts it.
+type Client struct {
+	URL        string        // URL of the server, e.g. "https://vpn.example.com:443"
+	Password   string        // Administrator password
+	Hub        string        // Virtual Hub to manage
+	HTTPClient *http.Client  // Defaults to http.DefaultClient
+	Timeout    time.Duration // Per-call timeout; zero means none besides the context
+
+	// Fingerprint is the SHA-256 fingerprint the server certificate must have, see
+	// softether.GetServerFingerprint. When set, the usual certificate verification
+	// is replaced by this check on every connection, and calls fail with
+	// softether.ErrCertMismatch if the server presents another certificate.
+	// HTTPClient must then use an *http.Transport, or none.
+	Fingerprint string
+
+	pinOnce sync.Once
+	pinned  *http.Client // HTTPClient with a transport which checks Fingerprint
+	pinErr  error
+}
+
+// fingerprintError is returned by the TLS handshake when the server certificate
+// does not have the pinned fingerprint.
+type fingerprintError struct {
+	got, want string
+}
+
+func (e *fingerprintError) Error() string {
+	return fmt.Sprintf("got fingerprint %s, want %s", e.got, e.want)
+}
+
+var _ softether.API = (*Client)(nil)
+
+type request struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      string      `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type response struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// call invokes the JSON-RPC method with params and decodes its result into result.
+// Errors are returned as *softether.Error values.
+func (c *Client) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	body, err := json.Marshal(request{JSONRPC: "2.0", ID: "rpc_call_id", Method: method, Params: params})
+	if err != nil {
+		return softether.NewKindError(softether.KindExec, method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(c.URL, "/")+"/api/", bytes.NewReader(body))
+	if err != nil {
+		return softether.NewKindError(softether.KindInvalidAddress, method, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-VPNADMIN-HUBNAME", "") // Administer the entire server
+	req.Header.Set("X-VPNADMIN-PASSWORD", c.Password)
+
+	httpClient, err := c.httpClient()
+	if err != nil {
+		return softether.NewKindError(softether.KindExec, method, err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return failure(ctx, method, err)
+	}
+	defer resp.Body.Close()
+
+	var rpcResponse response
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResponse); err != nil {
+		if ctx.Err() != nil {
+			return failure(ctx, method, err)
+		}
+		return softether.NewKindError(softether.KindParse, method, fmt.Errorf("HTTP %s: %v", resp.Status, err))
+	}
+
+	if rpcResponse.Error != nil {
+		rpcError := softether.NewError(rpcResponse.Error.Code)
+		rpcError.Command = method
+		if rpcResponse.Error.Message != "" {
+			rpcError.Message = rpcResponse.Error.Message
+		}
+		return rpcError
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(rpcResponse.Result, result); err != nil {
+			return softether.NewKindError(softether.KindParse, method, err)
+		}
+	}
+
+	return nil
+}
+
+// httpClient returns the HTTP client calls are made with: HTTPClient or, with a Fingerprint,
+// a copy of it whose transport only accepts a server certificate with that fingerprint.
+func (c *Client) httpClient() (*http.Client, error) {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if c.Fingerprint == "" {
+		return httpClient, nil
+	}
+
+	c.pinOnce.Do(func() {
+		transport, ok := httpClient.Transport.(*http.Transport)
+		if httpClient.Transport == nil {
+			transport, ok = http.DefaultTransport.(*http.Transport)
+		}
+		if !ok {
+			c.pinErr = fmt.Errorf("cannot pin the server certificate with a %T transport", httpClient.Transport)
+			return
+		}
+
+		transport = transport.Clone()
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.InsecureSkipVerify = true // The certificate is checked against the fingerprint
+		transport.TLSClientConfig.VerifyConnection = c.verifyConnection
+
+		pinned := *httpClient
+		pinned.Transport = transport
+		c.pinned = &pinned
+	})
+	return c.pinned, c.pinErr
+}
+
+// verifyConnection accepts a TLS connection only if the server presents a certificate
+// with the fingerprint c.Fingerprint.
+func (c *Client) verifyConnection(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return &fingerprintError{got: "none", want: c.Fingerprint}
+	}
+	if cert := state.PeerCertificates[0]; !softether.MatchFingerprint(cert, c.Fingerprint) {
+		return &fingerprintError{got: softether.Fingerprint(cert), want: c.Fingerprint}
+	}
+	return nil
+}
+
+// failure converts an error of the HTTP request for method into a *softether.Error.
+func failure(ctx context.Context, method string, err error) error {
+	var mismatch *fingerprintError
+	if errors.As(err, &mismatch) {
+		return softether.NewKindError(softether.KindCertMismatch, method, mismatch)
+	}
+
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return softether.NewKindError(softether.KindTimeout, method, ctx.Err())
+	case context.Canceled:
+		return softether.NewKindError(softether.KindCanceled, method, ctx.Err())
+	}
+
+	connectError := softether.NewError(1) // ERR_CONNECT_FAILED
+	connectError.Command = method
+	connectError.Err = err
+	return connectError
+}
+
+// hubParams returns the parameters selecting the Hub of c, plus extra.
+func (c *Client) hubParams(extra map[string]interface{}) map[string]interface{} {
+	params := map[string]interface{}{"HubName_str": c.Hub}
+	for key, value := range extra {
+		params[key] = value
+	}
+	return params
+}
+
+// GetServerStatus gets the server status info from the SoftEther server.
+func (c *Client) GetServerStatus(ctx context.Context) (status softether.ServerStatus, err error) {
+	var result serverStatus
+	if err = c.call(ctx, "GetServerStatus", map[string]interface{}{}, &result); err != nil {
+		return
+	}
+
+	status = result.toServerStatus()
+	return
+}
+
+// GetSessionList gets the session list of the Hub.
+func (c *Client) GetSessionList(ctx context.Context) (sessionList []softether.Session, err error) {
+	var result struct {
+		SessionList []sessionItem `json:"SessionList"`
+	}
+	if err = c.call(ctx, "EnumSession", c.hubParams(nil), &result); err != nil {
+		return
+	}
+
+	for _, item := range result.SessionList {
+		sessionList = append(sessionList, item.toSession())
+	}
+	return
+}
+
+// GetSessionInfo gets the session information for a specific Session Name.
+func (c *Client) GetSessionInfo(ctx context.Context, sessionName string) (session softether.Session, err error) {
+	var result sessionStatus
+	params := c.hubParams(map[string]interface{}{"Name_str": sessionName})
+	if err = c.call(ctx, "GetSessionStatus", params, &result); err != nil {
+		return
+	}
+
+	session = result.toSession()
+	return
+}
+
+// DisconnectSession disconnects a specific session.
+func (c *Client) DisconnectSession(ctx context.Context, sessionName string) error {
+	return c.call(ctx, "DeleteSession", c.hubParams(map[string]interface{}{"Name_str": sessionName}), nil)
+}
+
+// GetUserList gets the user list of the Hub.
+func (c *Client) GetUserList(ctx context.Context) (userList []softether.User, err error) {
+	var result struct {
+		UserList []userItem `json:"UserList"`
+	}
+	if err = c.call(ctx, "EnumUser", c.hubParams(nil), &result); err != nil {
+		return
+	}
+
+	for _, item := range result.UserList {
+		userList = append(userList, item.toUser())
+	}
+	return
+}
+
+// GetUserInfo gets the details of a specific User.
+func (c *Client) GetUserInfo(ctx context.Context, id string) (user softether.User, err error) {
+	var result userInfo
+	if err = c.call(ctx, "GetUser", c.hubParams(map[string]interface{}{"Name_str": id}), &result); err != nil {
+		return
+	}
+
+	user = result.toUser()
+	return
+}
+
+// CreateUser creates a User with an empty password. It takes the same parameters
+// as softether.SoftEther.CreateUser: id, email, and an optional description and group.
+func (c *Client) CreateUser(ctx context.Context, args ...interface{}) error {
+	if 2 > len(args) || len(args) > 4 {
+		panic("Wrong parameter count.")
+	}
+
+	// id, email, description and group
+	params := make([]string, 4)
+	for i, p := range args {
+		param, ok := p.(string)
+		if !ok {
+			panic(fmt.Sprintf("Parameter %d not type string.", i+1))
+		}
+		params[i] = param
+	}
+
+	return c.call(ctx, "CreateUser", c.hubParams(map[string]interface{}{
+		"Name_str":          params[0],
+		"Realname_utf":      params[1],
+		"Note_utf":          params[2],
+		"GroupName_str":     params[3],
+		"AuthType_u32":      authPassword,
+		"Auth_Password_str": "",
+	}), nil)
+}
+
+// updateUser reads the User id, applies changes and writes it back, preserving
+// all other settings.
+func (c *Client) updateUser(ctx context.Context, id string, changes map[string]interface{}) error {
+	user := make(map[string]interface{})
+	if err := c.call(ctx, "GetUser", c.hubParams(map[string]interface{}{"Name_str": id}), &user); err != nil {
+		return err
+	}
+
+	for key, value := range changes {
+		user[key] = value
+	}
+	user["HubName_str"] = c.Hub
+
+	return c.call(ctx, "SetUser", user, nil)
+}
+
+// SetUserPassword sets password authentication with password for a specific User.
+func (c *Client) SetUserPassword(ctx context.Context, id string, password string) error {
+	return c.updateUser(ctx, id, map[string]interface{}{
+		"AuthType_u32":      authPassword,
+		"Auth_Password_str": password,
+	})
+}
+
+// SetUserInfo updates the email and description of a specific User.
+func (c *Client) SetUserInfo(ctx context.Context, id, email, description string) error {
+	return c.updateUser(ctx, id, map[string]interface{}{
+		"Realname_utf": email,
+		"Note_utf":     description,
+	})
+}
+
+// SetUserEnabled enables or disables a specific User by clearing its expiration
+// date or setting it to one day before now.
+func (c *Client) SetUserEnabled(ctx context.Context, username string, enabled bool) error {
+	expires := time.Unix(0, 0)
+	if !enabled {
+		expires = time.Now().AddDate(0, 0, -1)
+	}
+
+	return c.updateUser(ctx, username, map[string]interface{}{
+		"ExpireTime_dt": formatTime(expires),
+	})
+}
+
+// DeleteUser deletes a specific User.
+func (c *Client) DeleteUser(ctx context.Context, id string) error {
+	return c.call(ctx, "DeleteUser", c.hubParams(map[string]interface{}{"Name_str": id}), nil)
+}
+
+// SetPreSharedKey changes the IPsec pre-shared key, keeping the rest of the IPsec configuration.
+func (c *Client) SetPreSharedKey(ctx context.Context, preSharedKey string) error {
+	config := make(map[string]interface{})
+	if err := c.call(ctx, "GetIPsecServices", map[string]interface{}{}, &config); err != nil {
+		return err
+	}
+
+	config["IPsec_Secret_str"] = preSharedKey
+	return c.call(ctx, "SetIPsecServices", config, nil)
+}
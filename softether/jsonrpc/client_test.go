@@ -0,0 +1,565 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether/jsonrpc"
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether/softethertest"
+)
+
+// newClient returns a Client for the Hub "subspace" of a local stand-in for the
+// JSON-RPC API replaying softethertest.JSONRPCFixtures.
+func newClient(t *testing.T) (*jsonrpc.Client, *softethertest.JSONRPCHandler) {
+	h := softethertest.NewJSONRPCHandler()
+	server := httptest.NewTLSServer(h)
+	t.Cleanup(server.Close)
+
+	client := &jsonrpc.Client{URL: server.URL, Password: "subspace", Hub: "subspace", HTTPClient: server.Client()}
+	return client, h
+}
+
+// date returns the time value, e.g. "2017-04-19 02:05:16", in UTC.
+func date(value string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04:05.999", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// checkError fails t unless err is the *softether.Error for the SoftEther error code errno.
+func checkError(t *testing.T, err error, errno int) {
+	t.Helper()
+
+	var rpcError *softether.Error
+	if !errors.As(err, &rpcError) {
+		t.Fatalf("err = %v, want *softether.Error with code %d", err, errno)
+	}
+	if !errors.Is(err, softether.NewError(errno)) {
+		t.Errorf("errors.Is(%v, NewError(%d)) = false", err, errno)
+	}
+	if rpcError.Name != softether.Strerror(errno) {
+		t.Errorf("err.Name = %q, want Strerror(%d) = %q", rpcError.Name, errno, softether.Strerror(errno))
+	}
+}
+
+// checkParams fails t unless params holds the values of want. JSON numbers are float64.
+func checkParams(t *testing.T, call softethertest.JSONRPCCall, want map[string]interface{}) {
+	t.Helper()
+
+	for key, value := range want {
+		if !reflect.DeepEqual(call.Params[key], value) {
+			t.Errorf("%s %s = %#v, want %#v", call.Method, key, call.Params[key], value)
+		}
+	}
+}
+
+// methods returns the JSON-RPC methods of calls.
+func methods(calls []softethertest.JSONRPCCall) []string {
+	var names []string
+	for _, call := range calls {
+		names = append(names, call.Method)
+	}
+	return names
+}
+
+func TestClientMethods(t *testing.T) {
+	tests := []struct {
+		name    string
+		call    func(ctx context.Context, c softether.API) error
+		methods []string               // JSON-RPC methods called
+		params  map[string]interface{} // Parameters of the last call
+		errno   int                    // error code the last method fails with
+	}{
+		{
+			name: "GetServerStatus",
+			call: func(ctx context.Context, c softether.API) error {
+				_, err := c.GetServerStatus(ctx)
+				return err
+			},
+			methods: []string{"GetServerStatus"},
+			params:  map[string]interface{}{},
+			errno:   52, // ERR_NOT_ENOUGH_RIGHT
+		},
+		{
+			name: "GetSessionList",
+			call: func(ctx context.Context, c softether.API) error {
+				_, err := c.GetSessionList(ctx)
+				return err
+			},
+			methods: []string{"EnumSession"},
+			params:  map[string]interface{}{"HubName_str": "subspace"},
+			errno:   8, // ERR_HUB_NOT_FOUND
+		},
+		{
+			name: "GetSessionInfo",
+			call: func(ctx context.Context, c softether.API) error {
+				_, err := c.GetSessionInfo(ctx, "SID-1-[L2TP]-2")
+				return err
+			},
+			methods: []string{"GetSessionStatus"},
+			params:  map[string]interface{}{"HubName_str": "subspace", "Name_str": "SID-1-[L2TP]-2"},
+			errno:   29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "DisconnectSession",
+			call: func(ctx context.Context, c softether.API) error {
+				return c.DisconnectSession(ctx, "SID-1-[L2TP]-2")
+			},
+			methods: []string{"DeleteSession"},
+			params:  map[string]interface{}{"HubName_str": "subspace", "Name_str": "SID-1-[L2TP]-2"},
+			errno:   29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "GetUserList",
+			call: func(ctx context.Context, c softether.API) error {
+				_, err := c.GetUserList(ctx)
+				return err
+			},
+			methods: []string{"EnumUser"},
+			params:  map[string]interface{}{"HubName_str": "subspace"},
+			errno:   8, // ERR_HUB_NOT_FOUND
+		},
+		{
+			name: "GetUserInfo",
+			call: func(ctx context.Context, c softether.API) error {
+				_, err := c.GetUserInfo(ctx, "1")
+				return err
+			},
+			methods: []string{"GetUser"},
+			params:  map[string]interface{}{"HubName_str": "subspace", "Name_str": "1"},
+			errno:   29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "CreateUser",
+			call: func(ctx context.Context, c softether.API) error {
+				return c.CreateUser(ctx, "3", "new@ecoworkinc.com", "Note", "staff")
+			},
+			methods: []string{"CreateUser"},
+			params: map[string]interface{}{
+				"HubName_str":       "subspace",
+				"Name_str":          "3",
+				"Realname_utf":      "new@ecoworkinc.com",
+				"Note_utf":          "Note",
+				"GroupName_str":     "staff",
+				"AuthType_u32":      float64(1),
+				"Auth_Password_str": "",
+			},
+			errno: 66, // ERR_USER_ALREADY_EXISTS
+		},
+		{
+			name: "SetUserPassword",
+			call: func(ctx context.Context, c softether.API) error {
+				return c.SetUserPassword(ctx, "1", "s3cret")
+			},
+			methods: []string{"GetUser", "SetUser"},
+			params: map[string]interface{}{
+				"HubName_str":       "subspace",
+				"Name_str":          "1",
+				"Realname_utf":      "test@ecoworkinc.com",
+				"Note_utf":          "New Account",
+				"NumLogin_u32":      float64(3),
+				"AuthType_u32":      float64(1),
+				"Auth_Password_str": "s3cret",
+			},
+			errno: 52, // ERR_NOT_ENOUGH_RIGHT
+		},
+		{
+			name: "SetUserInfo",
+			call: func(ctx context.Context, c softether.API) error {
+				return c.SetUserInfo(ctx, "1", "new@ecoworkinc.com", "Renamed")
+			},
+			methods: []string{"GetUser", "SetUser"},
+			params: map[string]interface{}{
+				"HubName_str":    "subspace",
+				"Name_str":       "1",
+				"Realname_utf":   "new@ecoworkinc.com",
+				"Note_utf":       "Renamed",
+				"CreatedTime_dt": "2017-04-19T01:00:00.000Z",
+				"AuthType_u32":   float64(1),
+			},
+			errno: 52, // ERR_NOT_ENOUGH_RIGHT
+		},
+		{
+			name: "SetUserEnabled",
+			call: func(ctx context.Context, c softether.API) error {
+				return c.SetUserEnabled(ctx, "1", true)
+			},
+			methods: []string{"GetUser", "SetUser"},
+			params: map[string]interface{}{
+				"HubName_str":   "subspace",
+				"Name_str":      "1",
+				"Realname_utf":  "test@ecoworkinc.com",
+				"ExpireTime_dt": "1970-01-01T00:00:00.000Z",
+			},
+			errno: 52, // ERR_NOT_ENOUGH_RIGHT
+		},
+		{
+			name: "DeleteUser",
+			call: func(ctx context.Context, c softether.API) error {
+				return c.DeleteUser(ctx, "1")
+			},
+			methods: []string{"DeleteUser"},
+			params:  map[string]interface{}{"HubName_str": "subspace", "Name_str": "1"},
+			errno:   29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "SetPreSharedKey",
+			call: func(ctx context.Context, c softether.API) error {
+				return c.SetPreSharedKey(ctx, "abcdefg")
+			},
+			methods: []string{"GetIPsecServices", "SetIPsecServices"},
+			params: map[string]interface{}{
+				"L2TP_Raw_bool":       false,
+				"L2TP_IPsec_bool":     true,
+				"EtherIP_IPsec_bool":  false,
+				"IPsec_Secret_str":    "abcdefg",
+				"L2TP_DefaultHub_str": "subspace",
+			},
+			errno: 52, // ERR_NOT_ENOUGH_RIGHT
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			client, h := newClient(t)
+			if err := test.call(ctx, client); err != nil {
+				t.Fatalf("err = %v", err)
+			}
+
+			calls := h.Calls()
+			if got := methods(calls); !reflect.DeepEqual(got, test.methods) {
+				t.Fatalf("called %q, want %q", got, test.methods)
+			}
+			for _, call := range calls {
+				if call.Password != "subspace" {
+					t.Errorf("%s sent password %q", call.Method, call.Password)
+				}
+			}
+			checkParams(t, calls[len(calls)-1], test.params)
+
+			client, h = newClient(t)
+			h.Fail(test.methods[len(test.methods)-1], test.errno)
+			checkError(t, test.call(ctx, client), test.errno)
+		})
+	}
+}
+
+func TestClientReadModifyWrite(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("updateUser keeps other settings", func(t *testing.T) {
+		client, h := newClient(t)
+		if err := client.SetUserPassword(ctx, "1", "s3cret"); err != nil {
+			t.Fatalf("err = %v", err)
+		}
+
+		calls := h.Calls()
+		get, set := calls[0].Params, calls[1].Params
+		if !reflect.DeepEqual(get, map[string]interface{}{"HubName_str": "subspace", "Name_str": "1"}) {
+			t.Errorf("GetUser params = %v", get)
+		}
+
+		// Every setting GetUser returned is written back, only the password changes
+		for key, value := range fixture(t, "GetUser") {
+			if key == "Auth_Password_str" {
+				continue
+			}
+			if !reflect.DeepEqual(set[key], value) {
+				t.Errorf("SetUser %s = %#v, want %#v from GetUser", key, set[key], value)
+			}
+		}
+	})
+
+	t.Run("failed read skips the write", func(t *testing.T) {
+		client, h := newClient(t)
+		h.Fail("GetUser", 29)
+
+		checkError(t, client.SetUserInfo(ctx, "1", "new@ecoworkinc.com", "Renamed"), 29)
+		if got := methods(h.Calls()); !reflect.DeepEqual(got, []string{"GetUser"}) {
+			t.Errorf("called %q, want only GetUser", got)
+		}
+	})
+
+	t.Run("disabled user expires before now", func(t *testing.T) {
+		client, h := newClient(t)
+		if err := client.SetUserEnabled(ctx, "1", false); err != nil {
+			t.Fatalf("err = %v", err)
+		}
+
+		expires, err := time.Parse(time.RFC3339, h.Calls()[1].Params["ExpireTime_dt"].(string))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !expires.Before(time.Now()) {
+			t.Errorf("ExpireTime_dt = %v, after now", expires)
+		}
+	})
+
+	t.Run("failed read skips the PSK write", func(t *testing.T) {
+		client, h := newClient(t)
+		h.Fail("GetIPsecServices", 52)
+
+		checkError(t, client.SetPreSharedKey(ctx, "abcdefg"), 52)
+		if got := methods(h.Calls()); !reflect.DeepEqual(got, []string{"GetIPsecServices"}) {
+			t.Errorf("called %q, want only GetIPsecServices", got)
+		}
+	})
+}
+
+// fixture returns the canned result of method as decoded JSON.
+func fixture(t *testing.T, method string) map[string]interface{} {
+	t.Helper()
+
+	result := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(softethertest.JSONRPCFixtures[method]), &result); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestClientResults(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newClient(t)
+
+	t.Run("GetServerStatus", func(t *testing.T) {
+		status, err := client.GetServerStatus(ctx)
+		if err != nil {
+			t.Fatalf("err = %v", err)
+		}
+
+		want := softether.ServerStatus{
+			ServerType:        "Standalone Server",
+			NumberOfSockets:   14,
+			NumberOfHubs:      1,
+			NumberOfSessions:  2,
+			NumberOfMACTables: 3,
+			NumberOfIPTables:  4,
+			NumberOfUsers:     2,
+			ServerStartTime:   date("2017-04-19 02:05:16"),
+			CurrentServerTime: date("2017-04-20 10:11:12.345"),
+			Traffic: softether.Traffic{
+				OutgoingUnicastPackets:   12340,
+				OutgoingUnicastBytes:     4734874,
+				OutgoingBroadcastPackets: 120,
+				OutgoingBroadcastBytes:   10240,
+				IncomingUnicastPackets:   23450,
+				IncomingUnicastBytes:     1234567,
+				IncomingBroadcastPackets: 230,
+				IncomingBroadcastBytes:   20480,
+			},
+		}
+		if !reflect.DeepEqual(status, want) {
+			t.Errorf("status = %+v, want %+v", status, want)
+		}
+	})
+
+	t.Run("GetSessionList", func(t *testing.T) {
+		sessionList, err := client.GetSessionList(ctx)
+		if err != nil {
+			t.Fatalf("err = %v", err)
+		}
+
+		want := []softether.Session{
+			{
+				Name:           "SID-SECURENAT-1",
+				Location:       "Local Session",
+				Username:       "SecureNAT",
+				ClientIP:       "0.0.0.0",
+				ClientHostName: "Virtual Host",
+			},
+			{
+				Name:              "SID-1-[L2TP]-2",
+				Location:          "Local Session",
+				Username:          "1",
+				ClientIP:          "203.0.113.10",
+				ClientHostName:    "203.0.113.10",
+				MaxTCPConnections: 1,
+				TransferBytes:     4734874,
+				TransferPackets:   12345,
+			},
+		}
+		if !reflect.DeepEqual(sessionList, want) {
+			t.Errorf("sessionList = %+v, want %+v", sessionList, want)
+		}
+	})
+
+	t.Run("GetSessionInfo", func(t *testing.T) {
+		session, err := client.GetSessionInfo(ctx, "SID-1-[L2TP]-2")
+		if err != nil {
+			t.Fatalf("err = %v", err)
+		}
+
+		if session.Name != "SID-1-[L2TP]-2" || session.Username != "1" || session.ClientPort != 4500 {
+			t.Errorf("session = %+v", session)
+		}
+		if session.Encryption != "Enabled (Algorithm: AES128-SHA)" || !session.Encrypted || session.Compressed {
+			t.Errorf("Encryption, Encrypted, Compressed = %q, %v, %v", session.Encryption, session.Encrypted, session.Compressed)
+		}
+		if session.ClientVersion != "1.00" || session.OutgoingDataSize != 4734874 || session.IncomingUnicastPackets != 2345 {
+			t.Errorf("ClientVersion, OutgoingDataSize, IncomingUnicastPackets = %q, %d, %d",
+				session.ClientVersion, session.OutgoingDataSize, session.IncomingUnicastPackets)
+		}
+		if !session.ConnectionStarted.Equal(date("2017-04-19 02:05:16")) {
+			t.Errorf("ConnectionStarted = %v", session.ConnectionStarted)
+		}
+	})
+
+	t.Run("GetUserList", func(t *testing.T) {
+		userList, err := client.GetUserList(ctx)
+		if err != nil {
+			t.Fatalf("err = %v", err)
+		}
+
+		want := []softether.User{
+			{
+				Name:            "1",
+				FullName:        "test@ecoworkinc.com",
+				Description:     "New Account",
+				AuthType:        softether.AuthPassword,
+				NumberOfLogins:  3,
+				LastLogin:       date("2017-04-19 02:05:16"),
+				TransferBytes:   5969441,
+				TransferPackets: 14696,
+			},
+			{
+				Name:           "2",
+				FullName:       "other@ecoworkinc.com",
+				Description:    "Other Account",
+				AuthType:       softether.AuthPassword,
+				ExpirationDate: date("2017-04-18 10:00:00"),
+			},
+		}
+		if !reflect.DeepEqual(userList, want) {
+			t.Errorf("userList = %+v, want %+v", userList, want)
+		}
+	})
+
+	t.Run("GetUserInfo", func(t *testing.T) {
+		user, err := client.GetUserInfo(ctx, "1")
+		if err != nil {
+			t.Fatalf("err = %v", err)
+		}
+
+		if user.Name != "1" || user.AuthType != softether.AuthPassword || user.NumberOfLogins != 3 {
+			t.Errorf("user = %+v", user)
+		}
+		if !user.ExpirationDate.IsZero() || !user.CreatedOn.Equal(date("2017-04-19 01:00:00")) {
+			t.Errorf("ExpirationDate, CreatedOn = %v, %v", user.ExpirationDate, user.CreatedOn)
+		}
+		if user.OutgoingUnicastBytes != 4724634 {
+			t.Errorf("OutgoingUnicastBytes = %d", user.OutgoingUnicastBytes)
+		}
+	})
+}
+
+func TestClientErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("message of the server", func(t *testing.T) {
+		client, h := newClient(t)
+		h.Fail("GetUser", 29)
+
+		_, err := client.GetUserInfo(ctx, "1")
+		checkError(t, err, 29)
+		if rpcError := err.(*softether.Error); rpcError.Command != "GetUser" || rpcError.Message != "object not found" {
+			t.Errorf("Command, Message = %q, %q", rpcError.Command, rpcError.Message)
+		}
+	})
+
+	t.Run("invalid result", func(t *testing.T) {
+		client, h := newClient(t)
+		h.Handle("EnumSession", `{"SessionList": [}`)
+
+		_, err := client.GetSessionList(ctx)
+		if !errors.Is(err, softether.ErrParse) {
+			t.Errorf("err = %v, want ErrParse", err)
+		}
+	})
+
+	t.Run("connection refused", func(t *testing.T) {
+		client, _ := newClient(t)
+		server := httptest.NewTLSServer(softethertest.NewJSONRPCHandler())
+		client.URL = server.URL
+		server.Close()
+
+		_, err := client.GetServerStatus(ctx)
+		checkError(t, err, 1)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		client, _ := newClient(t)
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := client.GetServerStatus(canceled)
+		if !errors.Is(err, softether.ErrCanceled) || !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want ErrCanceled", err)
+		}
+	})
+}
+
+func TestClientFingerprint(t *testing.T) {
+	ctx := context.Background()
+
+	h := softethertest.NewJSONRPCHandler()
+	server := httptest.NewTLSServer(h)
+	defer server.Close()
+	fingerprint := softether.Fingerprint(server.Certificate())
+
+	t.Run("match", func(t *testing.T) {
+		// The self-signed certificate is accepted without a TLS configuration trusting it
+		for _, httpClient := range []*http.Client{nil, {Transport: &http.Transport{}}} {
+			client := &jsonrpc.Client{URL: server.URL, Password: "subspace", Hub: "subspace", HTTPClient: httpClient, Fingerprint: fingerprint}
+			if _, err := client.GetServerStatus(ctx); err != nil {
+				t.Errorf("err = %v", err)
+			}
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		// Even a client trusting the certificate rejects it, before sending the password
+		calls := len(h.Calls())
+		client := &jsonrpc.Client{URL: server.URL, Password: "subspace", Hub: "subspace", HTTPClient: server.Client(), Fingerprint: strings.Repeat("00", 32)}
+
+		_, err := client.GetServerStatus(ctx)
+		var rpcError *softether.Error
+		if !errors.As(err, &rpcError) || rpcError.Kind != softether.KindCertMismatch || !errors.Is(err, softether.ErrCertMismatch) {
+			t.Fatalf("err = %v, want ErrCertMismatch", err)
+		}
+		if rpcError.Command != "GetServerStatus" || !strings.Contains(err.Error(), fingerprint) {
+			t.Errorf("err = %v, want the GetServerStatus call and the fingerprint %s", err, fingerprint)
+		}
+		if len(h.Calls()) != calls {
+			t.Errorf("the call reached the server: %v", h.Calls()[calls:])
+		}
+	})
+
+	t.Run("custom transport", func(t *testing.T) {
+		client := &jsonrpc.Client{URL: server.URL, HTTPClient: &http.Client{Transport: roundTripper{}}, Fingerprint: fingerprint}
+
+		_, err := client.GetServerStatus(ctx)
+		var rpcError *softether.Error
+		if !errors.As(err, &rpcError) || rpcError.Kind != softether.KindExec {
+			t.Errorf("err = %v, want a *softether.Error of kind %s", err, softether.KindExec)
+		}
+	})
+}
+
+// roundTripper is an http.RoundTripper the certificate cannot be pinned on.
+type roundTripper struct{}
+
+func (roundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
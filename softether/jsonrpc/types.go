@@ -0,0 +1,328 @@
+package jsonrpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+)
+
+// Authentication types of users, as in AuthType_u32
+const (
+	authAnonymous = iota
+	authPassword
+	authUserCert
+	authRootCert
+	authRadius
+	authNTDomain
+)
+
+// authTypes map the authentication types to the ones of the softether package
+var authTypes = map[uint32]softether.AuthType{
+	authAnonymous: softether.AuthAnonymous,
+	authPassword:  softether.AuthPassword,
+	authUserCert:  softether.AuthUserCert,
+	authRootCert:  softether.AuthSignedCert,
+	authRadius:    softether.AuthRadius,
+	authNTDomain:  softether.AuthNTDomain,
+}
+
+// serverTypes are the names vpncmd shows for ServerType_u32
+var serverTypes = map[uint32]string{
+	0: "Standalone Server",
+	1: "Cluster Controller",
+	2: "Cluster Member Server",
+}
+
+// dateTime is a _dt value. SoftEther uses the Unix epoch for unset times, which
+// is converted to the zero time.
+type dateTime struct {
+	time.Time
+}
+
+func (d *dateTime) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return err
+	}
+	if t.Unix() <= 0 {
+		t = time.Time{}
+	}
+	d.Time = t
+	return nil
+}
+
+// formatTime formats t as a _dt value.
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// formatVersion formats version numbers such as 422 the way vpncmd does ("4.22").
+func formatVersion(version uint32) string {
+	return fmt.Sprintf("%d.%02d", version/100, version%100)
+}
+
+// traffic holds the counters of RPC_TRAFFIC.
+type traffic struct {
+	RecvBroadcastBytes uint64 `json:"Recv.BroadcastBytes_u64"`
+	RecvBroadcastCount uint64 `json:"Recv.BroadcastCount_u64"`
+	RecvUnicastBytes   uint64 `json:"Recv.UnicastBytes_u64"`
+	RecvUnicastCount   uint64 `json:"Recv.UnicastCount_u64"`
+	SendBroadcastBytes uint64 `json:"Send.BroadcastBytes_u64"`
+	SendBroadcastCount uint64 `json:"Send.BroadcastCount_u64"`
+	SendUnicastBytes   uint64 `json:"Send.UnicastBytes_u64"`
+	SendUnicastCount   uint64 `json:"Send.UnicastCount_u64"`
+}
+
+func (t traffic) toTraffic() softether.Traffic {
+	return softether.Traffic{
+		OutgoingUnicastPackets:   int64(t.SendUnicastCount),
+		OutgoingUnicastBytes:     int64(t.SendUnicastBytes),
+		OutgoingBroadcastPackets: int64(t.SendBroadcastCount),
+		OutgoingBroadcastBytes:   int64(t.SendBroadcastBytes),
+		IncomingUnicastPackets:   int64(t.RecvUnicastCount),
+		IncomingUnicastBytes:     int64(t.RecvUnicastBytes),
+		IncomingBroadcastPackets: int64(t.RecvBroadcastCount),
+		IncomingBroadcastBytes:   int64(t.RecvBroadcastBytes),
+	}
+}
+
+// exTraffic holds the counters of RPC_TRAFFIC in list items, which are prefixed with "Ex.".
+type exTraffic struct {
+	RecvBroadcastBytes uint64 `json:"Ex.Recv.BroadcastBytes_u64"`
+	RecvBroadcastCount uint64 `json:"Ex.Recv.BroadcastCount_u64"`
+	RecvUnicastBytes   uint64 `json:"Ex.Recv.UnicastBytes_u64"`
+	RecvUnicastCount   uint64 `json:"Ex.Recv.UnicastCount_u64"`
+	SendBroadcastBytes uint64 `json:"Ex.Send.BroadcastBytes_u64"`
+	SendBroadcastCount uint64 `json:"Ex.Send.BroadcastCount_u64"`
+	SendUnicastBytes   uint64 `json:"Ex.Send.UnicastBytes_u64"`
+	SendUnicastCount   uint64 `json:"Ex.Send.UnicastCount_u64"`
+}
+
+func (t exTraffic) toTraffic() softether.Traffic {
+	return traffic(t).toTraffic()
+}
+
+// serverStatus is the result of GetServerStatus.
+type serverStatus struct {
+	ServerType        uint32   `json:"ServerType_u32"`
+	NumTcpConnections uint32   `json:"NumTcpConnections_u32"`
+	NumHubTotal       uint32   `json:"NumHubTotal_u32"`
+	NumSessionsTotal  uint32   `json:"NumSessionsTotal_u32"`
+	NumMacTables      uint32   `json:"NumMacTables_u32"`
+	NumIpTables       uint32   `json:"NumIpTables_u32"`
+	NumUsers          uint32   `json:"NumUsers_u32"`
+	NumGroups         uint32   `json:"NumGroups_u32"`
+	StartTime         dateTime `json:"StartTime_dt"`
+	CurrentTime       dateTime `json:"CurrentTime_dt"`
+	traffic
+}
+
+func (s serverStatus) toServerStatus() softether.ServerStatus {
+	return softether.ServerStatus{
+		ServerType:        serverTypes[s.ServerType],
+		NumberOfSockets:   int(s.NumTcpConnections),
+		NumberOfHubs:      int(s.NumHubTotal),
+		NumberOfSessions:  int(s.NumSessionsTotal),
+		NumberOfMACTables: int(s.NumMacTables),
+		NumberOfIPTables:  int(s.NumIpTables),
+		NumberOfUsers:     int(s.NumUsers),
+		NumberOfGroups:    int(s.NumGroups),
+		ServerStartTime:   s.StartTime.Time,
+		CurrentServerTime: s.CurrentTime.Time,
+		Traffic:           s.traffic.toTraffic(),
+	}
+}
+
+// sessionItem is an item of the SessionList of EnumSession.
+type sessionItem struct {
+	Name              string `json:"Name_str"`
+	RemoteSession     bool   `json:"RemoteSession_bool"`
+	RemoteHostname    string `json:"RemoteHostname_str"`
+	Username          string `json:"Username_str"`
+	ClientIP          string `json:"ClientIP_ip"`
+	Hostname          string `json:"Hostname_str"`
+	MaxNumTcp         uint32 `json:"MaxNumTcp_u32"`
+	CurrentNumTcp     uint32 `json:"CurrentNumTcp_u32"`
+	PacketSize        uint64 `json:"PacketSize_u64"`
+	PacketNum         uint64 `json:"PacketNum_u64"`
+	ClientBridgeMode  bool   `json:"Client_BridgeMode_bool"`
+	ClientMonitorMode bool   `json:"Client_MonitorMode_bool"`
+	VLanID            uint32 `json:"VLanId_u32"`
+}
+
+func (s sessionItem) toSession() softether.Session {
+	location := "Local Session"
+	if s.RemoteSession {
+		location = s.RemoteHostname
+	}
+
+	return softether.Session{
+		Name:              s.Name,
+		VLANID:            int(s.VLanID),
+		Location:          location,
+		Username:          s.Username,
+		ClientIP:          s.ClientIP,
+		ClientHostName:    s.Hostname,
+		TCPConnections:    int(s.CurrentNumTcp),
+		MaxTCPConnections: int(s.MaxNumTcp),
+		BridgeMode:        s.ClientBridgeMode,
+		MonitorMode:       s.ClientMonitorMode,
+		TransferBytes:     int64(s.PacketSize),
+		TransferPackets:   int64(s.PacketNum),
+	}
+}
+
+// sessionStatus is the result of GetSessionStatus.
+type sessionStatus struct {
+	Name                  string   `json:"Name_str"`
+	Username              string   `json:"Username_str"`
+	ClientIP              string   `json:"Client_Ip_Address_ip"`
+	ClientHostName        string   `json:"SessionStatus_ClientHostName_str"`
+	ClientPort            uint32   `json:"ClientPort_u32"`
+	ClientProductName     string   `json:"ClientProductName_str"`
+	ClientProductVer      uint32   `json:"ClientProductVer_u32"`
+	ClientOsName          string   `json:"ClientOsName_str"`
+	ServerHostname        string   `json:"ServerHostname_str"`
+	ServerIPAddress       string   `json:"ServerIpAddress_ip"`
+	ServerPort            uint32   `json:"ServerPort_u32"`
+	UseEncrypt            bool     `json:"UseEncrypt_bool"`
+	CipherName            string   `json:"CipherName_str"`
+	UseCompress           bool     `json:"UseCompress_bool"`
+	HalfConnection        bool     `json:"HalfConnection_bool"`
+	NumTcpConnections     uint32   `json:"NumTcpConnections_u32"`
+	MaxTcpConnections     uint32   `json:"MaxTcpConnections_u32"`
+	IsBridgeMode          bool     `json:"IsBridgeMode_bool"`
+	IsMonitorMode         bool     `json:"IsMonitorMode_bool"`
+	IsUsingUdpAccel       bool     `json:"IsUsingUdpAcceleration_bool"`
+	TotalSendSize         uint64   `json:"TotalSendSize_u64"`
+	TotalRecvSize         uint64   `json:"TotalRecvSize_u64"`
+	StartTime             dateTime `json:"StartTime_dt"`
+	FirstConnectionTime   dateTime `json:"FirstConnectionEstablisiedTime_dt"`
+	CurrentConnectionTime dateTime `json:"CurrentConnectionEstablishTime_dt"`
+	traffic
+}
+
+func (s sessionStatus) toSession() softether.Session {
+	session := softether.Session{
+		Name:               s.Name,
+		Username:           s.Username,
+		ClientIP:           s.ClientIP,
+		ClientHostName:     s.ClientHostName,
+		ClientPort:         int(s.ClientPort),
+		ClientProduct:      s.ClientProductName,
+		ClientVersion:      formatVersion(s.ClientProductVer),
+		ClientOS:           s.ClientOsName,
+		ServerHostName:     s.ServerHostname,
+		ServerIP:           s.ServerIPAddress,
+		ServerPort:         int(s.ServerPort),
+		Encryption:         "Disabled",
+		Compression:        "No",
+		TCPConnections:     int(s.NumTcpConnections),
+		MaxTCPConnections:  int(s.MaxTcpConnections),
+		Encrypted:          s.UseEncrypt,
+		Compressed:         s.UseCompress,
+		HalfDuplex:         s.HalfConnection,
+		BridgeMode:         s.IsBridgeMode,
+		MonitorMode:        s.IsMonitorMode,
+		UDPAcceleration:    s.IsUsingUdpAccel,
+		OutgoingDataSize:   int64(s.TotalSendSize),
+		IncomingDataSize:   int64(s.TotalRecvSize),
+		ConnectionStarted:  s.StartTime.Time,
+		FirstEstablished:   s.FirstConnectionTime.Time,
+		CurrentEstablished: s.CurrentConnectionTime.Time,
+		Traffic:            s.traffic.toTraffic(),
+	}
+	if s.UseEncrypt {
+		session.Encryption = "Enabled (Algorithm: " + s.CipherName + ")"
+	}
+	if s.UseCompress {
+		session.Compression = "Yes"
+	}
+	return session
+}
+
+// userItem is an item of the UserList of EnumUser.
+type userItem struct {
+	Name            string   `json:"Name_str"`
+	GroupName       string   `json:"GroupName_str"`
+	Realname        string   `json:"Realname_utf"`
+	Note            string   `json:"Note_utf"`
+	AuthType        uint32   `json:"AuthType_u32"`
+	NumLogin        uint32   `json:"NumLogin_u32"`
+	LastLoginTime   dateTime `json:"LastLoginTime_dt"`
+	IsExpiresFilled bool     `json:"IsExpiresFilled_bool"`
+	Expires         dateTime `json:"Expires_dt"`
+	exTraffic
+}
+
+func (u userItem) toUser() softether.User {
+	t := u.exTraffic.toTraffic()
+	user := softether.User{
+		Name:            u.Name,
+		FullName:        u.Realname,
+		Description:     u.Note,
+		GroupName:       u.GroupName,
+		AuthType:        authTypes[u.AuthType],
+		NumberOfLogins:  int(u.NumLogin),
+		LastLogin:       u.LastLoginTime.Time,
+		TransferBytes:   t.IncomingBytes() + t.OutgoingBytes(),
+		TransferPackets: t.IncomingUnicastPackets + t.IncomingBroadcastPackets + t.OutgoingUnicastPackets + t.OutgoingBroadcastPackets,
+	}
+	if u.IsExpiresFilled {
+		user.ExpirationDate = u.Expires.Time
+	}
+	return user
+}
+
+// userInfo is the result of GetUser.
+type userInfo struct {
+	Name        string   `json:"Name_str"`
+	GroupName   string   `json:"GroupName_str"`
+	Realname    string   `json:"Realname_utf"`
+	Note        string   `json:"Note_utf"`
+	CreatedTime dateTime `json:"CreatedTime_dt"`
+	UpdatedTime dateTime `json:"UpdatedTime_dt"`
+	ExpireTime  dateTime `json:"ExpireTime_dt"`
+	AuthType    uint32   `json:"AuthType_u32"`
+	NumLogin    uint32   `json:"NumLogin_u32"`
+	CommonName  string   `json:"CommonName_utf"`
+	Serial      []byte   `json:"Serial_bin"`
+	RadiusName  string   `json:"RadiusUsername_utf"`
+	NtName      string   `json:"NtUsername_utf"`
+	traffic
+}
+
+func (u userInfo) toUser() softether.User {
+	user := softether.User{
+		Name:           u.Name,
+		FullName:       u.Realname,
+		Description:    u.Note,
+		GroupName:      u.GroupName,
+		AuthType:       authTypes[u.AuthType],
+		NumberOfLogins: int(u.NumLogin),
+		ExpirationDate: u.ExpireTime.Time,
+		CreatedOn:      u.CreatedTime.Time,
+		UpdatedOn:      u.UpdatedTime.Time,
+		Traffic:        u.traffic.toTraffic(),
+	}
+
+	switch u.AuthType {
+	case authRootCert:
+		user.CertCommonName = u.CommonName
+		user.CertSerial = hex.EncodeToString(u.Serial)
+	case authRadius:
+		user.AuthAlias = u.RadiusName
+	case authNTDomain:
+		user.AuthAlias = u.NtName
+	}
+
+	return user
+}
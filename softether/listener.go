@@ -0,0 +1,91 @@
+package softether
+
+import (
+	"context"
+	"strconv"
+)
+
+// ListenerStatus is the status of a TCP listener of the SoftEther server.
+type ListenerStatus string
+
+// Listener statuses.
+const (
+	ListenerListening ListenerStatus = "Listening"
+	ListenerStopped   ListenerStatus = "Stopped" // Disabled with DisableListener
+	ListenerError     ListenerStatus = "Error"   // Enabled, but the port could not be opened, e.g. because it is in use
+)
+
+// Listener is a TCP port the SoftEther server accepts VPN and admin connections on,
+// as listed by ListListeners.
+type Listener struct {
+	Port   int
+	Status ListenerStatus
+}
+
+// parseListener converts a row of ListenerList, e.g. "TCP 443,Listening".
+func parseListener(m map[string]string) Listener {
+	return Listener{
+		Port:   parseInt(reFindIntegers.FindString(m["Port Number"])),
+		Status: ListenerStatus(parseString(m["Status"])),
+	}
+}
+
+// ListListeners executes vpncmd and gets the TCP listeners of the SoftEther server.
+func (s SoftEther) ListListeners(ctx context.Context) (listenerList []Listener, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /cmd ListenerList
+	cmd := s.command("ListenerList")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	rows, err := parseTable(cmd, cmdOutput, "Port Number")
+	for _, row := range rows {
+		listenerList = append(listenerList, parseListener(row))
+	}
+
+	return
+}
+
+// CreateListener executes vpncmd and adds a TCP listener on port to the SoftEther server.
+// It fails with ErrListenerAlreadyExists if there is one already.
+func (s SoftEther) CreateListener(ctx context.Context, port int) (err error) {
+	return s.listenerCommand(ctx, "ListenerCreate", port)
+}
+
+// DeleteListener executes vpncmd and removes the TCP listener on port from the SoftEther server.
+// It fails with ErrListenerNotFound if there is none.
+func (s SoftEther) DeleteListener(ctx context.Context, port int) (err error) {
+	return s.listenerCommand(ctx, "ListenerDelete", port)
+}
+
+// EnableListener executes vpncmd and starts the stopped TCP listener on port.
+// It fails with ErrListenerNotFound if there is none.
+func (s SoftEther) EnableListener(ctx context.Context, port int) (err error) {
+	return s.listenerCommand(ctx, "ListenerEnable", port)
+}
+
+// DisableListener executes vpncmd and stops the TCP listener on port without removing it.
+// It fails with ErrListenerNotFound if there is none.
+func (s SoftEther) DisableListener(ctx context.Context, port int) (err error) {
+	return s.listenerCommand(ctx, "ListenerDisable", port)
+}
+
+// listenerCommand executes the vpncmd command name, which takes a listener port.
+func (s SoftEther) listenerCommand(ctx context.Context, name string, port int) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /cmd [ListenerCreate|ListenerDelete|ListenerEnable|ListenerDisable] [PORT]
+	cmd := s.command(
+		name,
+		strconv.Itoa(port),
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
@@ -0,0 +1,89 @@
+package softether_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+)
+
+func TestListenerCommands(t *testing.T) {
+	runCommandTests(t, []commandTest{
+		{
+			name: "ListListeners",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.ListListeners(ctx)
+				return err
+			},
+			want:  [][]string{{"ListenerList"}},
+			errno: 52, // ERR_NOT_ENOUGH_RIGHT
+		},
+		{
+			name: "CreateListener",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.CreateListener(ctx, 8443)
+			},
+			want:  [][]string{{"ListenerCreate", "8443"}},
+			errno: 54, // ERR_LISTENER_ALREADY_EXISTS
+		},
+		{
+			name: "DeleteListener",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.DeleteListener(ctx, 5555)
+			},
+			want:  [][]string{{"ListenerDelete", "5555"}},
+			errno: 53, // ERR_LISTENER_NOT_FOUND
+		},
+		{
+			name: "EnableListener",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.EnableListener(ctx, 5555)
+			},
+			want:  [][]string{{"ListenerEnable", "5555"}},
+			errno: 53, // ERR_LISTENER_NOT_FOUND
+		},
+		{
+			name: "DisableListener",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.DisableListener(ctx, 5555)
+			},
+			want:  [][]string{{"ListenerDisable", "5555"}},
+			errno: 53, // ERR_LISTENER_NOT_FOUND
+		},
+	})
+}
+
+func TestListListeners(t *testing.T) {
+	s, _ := newServer()
+
+	listenerList, err := s.ListListeners(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []softether.Listener{
+		{Port: 443, Status: softether.ListenerListening},
+		{Port: 992, Status: softether.ListenerListening},
+		{Port: 1194, Status: softether.ListenerListening},
+		{Port: 5555, Status: softether.ListenerStopped},
+		{Port: 8888, Status: softether.ListenerError},
+	}
+	if !reflect.DeepEqual(listenerList, want) {
+		t.Errorf("listenerList = %+v, want %+v", listenerList, want)
+	}
+}
+
+func TestCreateListenerAlreadyExists(t *testing.T) {
+	s, runner := newServer()
+	runner.Fail("ListenerCreate", 54)
+
+	err := s.CreateListener(context.Background(), 443)
+	if !errors.Is(err, softether.ErrListenerAlreadyExists) {
+		t.Errorf("err = %v, want ErrListenerAlreadyExists", err)
+	}
+	if errors.Is(err, softether.ErrListenerNotFound) {
+		t.Errorf("err = %v matches ErrListenerNotFound", err)
+	}
+}
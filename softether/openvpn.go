@@ -0,0 +1,140 @@
+package softether
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// OpenVPNConfig is the configuration of the OpenVPN clone server function of the SoftEther server.
+type OpenVPNConfig struct {
+	Enabled  bool
+	UDPPorts []int // UDP ports OpenVPN clients connect to; TCP clients use the admin ports
+}
+
+// parseOpenVPNConfig converts the output table of OpenVpnGet.
+func parseOpenVPNConfig(m map[string]string) OpenVPNConfig {
+	config := OpenVPNConfig{
+		Enabled: parseBool(m["OpenVPN Clone Server Enabled"]),
+	}
+	for _, port := range reFindIntegers.FindAllString(m["UDP Port List"], -1) {
+		config.UDPPorts = append(config.UDPPorts, parseInt(port))
+	}
+	return config
+}
+
+// GetOpenVPNConfig executes vpncmd and gets the OpenVPN clone server configuration of the SoftEther server.
+func (s SoftEther) GetOpenVPNConfig(ctx context.Context) (config OpenVPNConfig, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /cmd OpenVpnGet
+	cmd := s.command("OpenVpnGet")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	configMap, err := parseKeyValue(cmd, cmdOutput)
+	if err != nil {
+		return
+	}
+
+	config = parseOpenVPNConfig(configMap)
+	return
+}
+
+// SetOpenVPNConfig executes vpncmd and updates the OpenVPN clone server configuration of the SoftEther server.
+func (s SoftEther) SetOpenVPNConfig(ctx context.Context, config OpenVPNConfig) (err error) {
+	ports := make([]string, 0, len(config.UDPPorts))
+	for _, port := range config.UDPPorts {
+		ports = append(ports, strconv.Itoa(port))
+	}
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /cmd OpenVpnEnable [yes|no] /PORTS:[PORT,PORT,...]
+	cmd := s.command(
+		"OpenVpnEnable",
+		formatBool(config.Enabled),
+		"/PORTS:"+strings.Join(ports, ","),
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// GenerateOpenVPNConfig executes vpncmd and generates the OpenVPN client configuration
+// files for the user username of the Virtual Hub hub, keyed by file name, e.g.
+// "vpn_openvpn_remote_access_l3.ovpn", along with the user name to log in with:
+// "username@hub", or "username" for the default Hub if hub is empty. The files are
+// returned as vpncmd generates them, so clients prompt for that login and the password.
+//
+// vpncmd writes the files to a ZIP file, which is read through a temporary directory.
+func (s SoftEther) GenerateOpenVPNConfig(ctx context.Context, hub, username string) (files map[string][]byte, login string, err error) {
+	dir, err := tempDir("OpenVpnMakeConfig")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+	zipFile := filepath.Join(dir, "openvpn.zip")
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /cmd OpenVpnMakeConfig [ZIP_FILE]
+	cmd := s.command(
+		"OpenVpnMakeConfig",
+		zipFile,
+	)
+
+	// Execute
+	if _, err = s.execute(ctx, cmd); err != nil {
+		return
+	}
+
+	// Extract data
+	data, err := os.ReadFile(zipFile)
+	if err != nil {
+		return nil, "", parseFailure(cmd, "no ZIP file saved: %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, "", parseFailure(cmd, "invalid ZIP file: %v", err)
+	}
+
+	files = make(map[string][]byte)
+	for _, f := range archive.File {
+		if !strings.HasSuffix(f.Name, ".ovpn") {
+			continue // Skip the readme
+		}
+
+		r, openErr := f.Open()
+		if openErr != nil {
+			return nil, "", parseFailure(cmd, "invalid ZIP file: %v", openErr)
+		}
+		config, readErr := io.ReadAll(r)
+		r.Close()
+		if readErr != nil {
+			return nil, "", parseFailure(cmd, "invalid ZIP file: %v", readErr)
+		}
+
+		files[path.Base(f.Name)] = config
+	}
+
+	if len(files) == 0 {
+		return nil, "", parseFailure(cmd, "no .ovpn file in ZIP file")
+	}
+
+	login = username
+	if hub != "" {
+		login += "@" + hub
+	}
+	return
+}
@@ -0,0 +1,153 @@
+package softether_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether/softethertest"
+)
+
+func TestOpenVPNCommands(t *testing.T) {
+	runCommandTests(t, []commandTest{
+		{
+			name: "GetOpenVPNConfig",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.GetOpenVPNConfig(ctx)
+				return err
+			},
+			want:  [][]string{{"OpenVpnGet"}},
+			errno: 52, // ERR_NOT_ENOUGH_RIGHT
+		},
+		{
+			name: "SetOpenVPNConfig",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetOpenVPNConfig(ctx, softether.OpenVPNConfig{Enabled: true, UDPPorts: []int{1194, 1195}})
+			},
+			want:  [][]string{{"OpenVpnEnable", "yes", "/PORTS:1194,1195"}},
+			errno: 38, // ERR_INVALID_PARAMETER
+		},
+		{
+			name: "SetOpenVPNConfig disabled",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetOpenVPNConfig(ctx, softether.OpenVPNConfig{})
+			},
+			want:  [][]string{{"OpenVpnEnable", "no", "/PORTS:"}},
+			errno: 38, // ERR_INVALID_PARAMETER
+		},
+	})
+}
+
+func TestGetOpenVPNConfig(t *testing.T) {
+	s, _ := newServer()
+
+	config, err := s.GetOpenVPNConfig(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := softether.OpenVPNConfig{Enabled: true, UDPPorts: []int{1194, 1195}}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("config = %+v, want %+v", config, want)
+	}
+}
+
+// zipFile returns a ZIP file holding files, keyed by name.
+func zipFile(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestGenerateOpenVPNConfig(t *testing.T) {
+	s, runner := newServer()
+
+	files, login, err := s.GenerateOpenVPNConfig(context.Background(), "tenant", "alice")
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if login != "alice@tenant" {
+		t.Errorf("login = %q, want alice@tenant", login)
+	}
+
+	// The ZIP file is saved to a temporary file, which is gone by now
+	commands := runner.Commands()
+	if len(commands) != 1 || commands[0].Name != "OpenVpnMakeConfig" || len(commands[0].Args) != 1 ||
+		!strings.HasSuffix(commands[0].Args[0], ".zip") || commands[0].Hub != "" {
+		t.Fatalf("commands = %v, want OpenVpnMakeConfig [ZIP_FILE]", commands)
+	}
+	if _, err := os.Stat(commands[0].Args[0]); !os.IsNotExist(err) {
+		t.Errorf("%s is left behind: %v", commands[0].Args[0], err)
+	}
+
+	// The readme is skipped, and the auth-user-pass directive is left in place
+	if len(files) != 2 || files["vpn_openvpn_remote_access_l3.ovpn"] == nil || files["vpn_openvpn_site_to_site_bridge_l2.ovpn"] == nil {
+		t.Fatalf("files = %q", files)
+	}
+	for name, config := range files {
+		text := string(config)
+		if !strings.Contains(text, "auth SHA1\r\nauth-user-pass\r\n") || strings.Contains(text, "<auth-user-pass>") {
+			t.Errorf("%s = %q", name, text)
+		}
+	}
+}
+
+func TestGenerateOpenVPNConfigLogin(t *testing.T) {
+	// The auth-user-pass directive is left for the client to prompt for the login
+	config := "client\r\nauth-user-pass\r\nremote vpn.example.com 443\r\n"
+
+	for hub, want := range map[string]string{"subspace": "alice@subspace", "": "alice"} {
+		s, runner := newServer()
+		runner.Handle("OpenVpnMakeConfig", softethertest.Response{File: zipFile(t, map[string]string{"vpn.ovpn": config})})
+
+		files, login, err := s.GenerateOpenVPNConfig(context.Background(), hub, "alice")
+		if err != nil {
+			t.Fatalf("err = %v", err)
+		}
+		if login != want {
+			t.Errorf("Hub %q: login = %q, want %q", hub, login, want)
+		}
+		if got := string(files["vpn.ovpn"]); got != config {
+			t.Errorf("Hub %q: vpn.ovpn = %q, want %q", hub, got, config)
+		}
+	}
+}
+
+func TestGenerateOpenVPNConfigFailure(t *testing.T) {
+	s, runner := newServer()
+	runner.Fail("OpenVpnMakeConfig", 52)
+	_, _, err := s.GenerateOpenVPNConfig(context.Background(), "subspace", "alice")
+	checkError(t, err, 52)
+
+	for name, file := range map[string]string{
+		"no file":        "",
+		"not a ZIP file": "not a ZIP file",
+		"no .ovpn file":  zipFile(t, map[string]string{"readme.txt": "OpenVPN configuration files\r\n"}),
+	} {
+		s, runner := newServer()
+		runner.Handle("OpenVpnMakeConfig", softethertest.Response{File: file})
+
+		files, login, err := s.GenerateOpenVPNConfig(context.Background(), "subspace", "alice")
+		if files != nil || login != "" {
+			t.Errorf("%s: files, login = %q, %q, want nil", name, files, login)
+		}
+		checkKind(t, err, softether.KindParse)
+	}
+}
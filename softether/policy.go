@@ -0,0 +1,252 @@
+package softether
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+)
+
+// Policy is a security policy, which restricts the sessions of a User or of the members
+// of a Group. Integer limits of 0 mean unlimited. Each field is tagged with its policy
+// name and the title UserGet and GroupGet show next to it.
+type Policy struct {
+	Access                          bool `policy:"Access" title:"Allow Access"`
+	DHCPFilter                      bool `policy:"DHCPFilter" title:"Filter DHCP Packets (IPv4)"`
+	DHCPNoServer                    bool `policy:"DHCPNoServer" title:"Disallow DHCP Server Operation (IPv4)"`
+	DHCPForce                       bool `policy:"DHCPForce" title:"Enforce DHCP Allocated IP Addresses (IPv4)"`
+	NoBridge                        bool `policy:"NoBridge" title:"Deny Bridge Operation"`
+	NoRouting                       bool `policy:"NoRouting" title:"Deny Routing Operation (IPv4)"`
+	CheckMAC                        bool `policy:"CheckMac" title:"Deny MAC Addresses Duplication"`
+	CheckIP                         bool `policy:"CheckIP" title:"Deny IP Address Duplication (IPv4)"`
+	ArpDHCPOnly                     bool `policy:"ArpDhcpOnly" title:"Deny Non-ARP / Non-DHCP / Non-ICMPv6 broadcasts"`
+	PrivacyFilter                   bool `policy:"PrivacyFilter" title:"Privacy Filter Mode"`
+	NoServer                        bool `policy:"NoServer" title:"Deny Operation as TCP/IP Server (IPv4)"`
+	NoBroadcastLimiter              bool `policy:"NoBroadcastLimiter" title:"Unlimited Number of Broadcasts"`
+	MonitorPort                     bool `policy:"MonitorPort" title:"Allow Monitoring Mode"`
+	MaxConnection                   int  `policy:"MaxConnection" title:"Maximum Number of TCP Connections"`
+	TimeOut                         int  `policy:"TimeOut" title:"Time-out Period"` // Seconds
+	MaxMAC                          int  `policy:"MaxMac" title:"Maximum Number of MAC Addresses"`
+	MaxIP                           int  `policy:"MaxIP" title:"Maximum Number of IP Addresses (IPv4)"`
+	MaxUpload                       int  `policy:"MaxUpload" title:"Upload Bandwidth"`     // Bits per second
+	MaxDownload                     int  `policy:"MaxDownload" title:"Download Bandwidth"` // Bits per second
+	FixPassword                     bool `policy:"FixPassword" title:"Deny Changing Password"`
+	MultiLogins                     int  `policy:"MultiLogins" title:"Maximum Number of Multiple Logins"`
+	NoQoS                           bool `policy:"NoQoS" title:"Deny VoIP / QoS Function"`
+	RSandRAFilter                   bool `policy:"RSandRAFilter" title:"Filter RS / RA Packets (IPv6)"`
+	RAFilter                        bool `policy:"RAFilter" title:"Filter RA Packets (IPv6)"`
+	DHCPv6Filter                    bool `policy:"DHCPv6Filter" title:"Filter DHCP Packets (IPv6)"`
+	DHCPv6NoServer                  bool `policy:"DHCPv6NoServer" title:"Disallow DHCP Server Operation (IPv6)"`
+	NoRoutingV6                     bool `policy:"NoRoutingV6" title:"Deny Routing Operation (IPv6)"`
+	CheckIPv6                       bool `policy:"CheckIPv6" title:"Deny IP Address Duplication (IPv6)"`
+	NoServerV6                      bool `policy:"NoServerV6" title:"Deny Operation as TCP/IP Server (IPv6)"`
+	MaxIPv6                         int  `policy:"MaxIPv6" title:"Maximum Number of IP Addresses (IPv6)"`
+	NoSavePassword                  bool `policy:"NoSavePassword" title:"Disallow Password Save in VPN Client"`
+	AutoDisconnect                  int  `policy:"AutoDisconnect" title:"VPN Client Automatic Disconnect"` // Seconds
+	FilterIPv4                      bool `policy:"FilterIPv4" title:"Filter All IPv4 Packets"`
+	FilterIPv6                      bool `policy:"FilterIPv6" title:"Filter All IPv6 Packets"`
+	FilterNonIP                     bool `policy:"FilterNonIP" title:"Filter All Non-IP Packets"`
+	NoIPv6DefaultRouterInRA         bool `policy:"NoIPv6DefaultRouterInRA" title:"No Default-Router on IPv6 RA"`
+	NoIPv6DefaultRouterInRAWhenIPv6 bool `policy:"NoIPv6DefaultRouterInRAWhenIPv6" title:"No Default-Router on IPv6 RA (physical IPv6)"`
+	VLANID                          int  `policy:"VLanId" title:"VLAN ID (IEEE802.1Q)"`
+}
+
+// DefaultPolicy returns the policy SoftEther assigns to a User or Group when the
+// first policy item is set. Start from it to only change some of the items.
+func DefaultPolicy() Policy {
+	return Policy{
+		Access:        true,
+		MaxConnection: 32,
+		TimeOut:       20,
+	}
+}
+
+// policyNameColumn is the first column of the policy table UserGet and GroupGet print.
+const policyNameColumn = "Policy name"
+
+// parsePolicy reads the security policy out of the output of UserGet or GroupGet, which
+// print it after the User or group table, one row of policy name, title and value per item.
+// Disabled items and unlimited values are printed as "-". It returns nil if the output
+// holds no policy table.
+func parsePolicy(cmd Command, output []byte) (policy *Policy, err error) {
+	records, err := readCSV(cmd, output)
+	if err != nil {
+		return
+	}
+
+	values := make(map[string]string)
+	inTable := false
+	for _, record := range records {
+		if len(record) != 3 {
+			inTable = false // Not part of the policy table
+			continue
+		}
+		if record[0] == policyNameColumn {
+			inTable = true
+			continue
+		}
+		if inTable {
+			values[record[0]] = record[2]
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+
+	policy = &Policy{}
+	v := reflect.ValueOf(policy).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		value, ok := values[v.Type().Field(i).Tag.Get("policy")]
+		if !ok {
+			continue
+		}
+
+		switch field := v.Field(i); field.Kind() {
+		case reflect.Bool:
+			field.SetBool(parseBool(value))
+		case reflect.Int:
+			field.SetInt(parseCount(value))
+		}
+	}
+
+	return
+}
+
+// policyChanges returns the name and vpncmd value of each item of policy which differs
+// from current, or of all items if current is nil.
+func policyChanges(current *Policy, policy Policy) (names, values []string) {
+	v := reflect.ValueOf(policy)
+	var c reflect.Value
+	if current != nil {
+		c = reflect.ValueOf(*current)
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if current != nil && field.Interface() == c.Field(i).Interface() {
+			continue
+		}
+
+		var value string
+		switch field.Kind() {
+		case reflect.Bool:
+			value = formatBool(field.Bool())
+		case reflect.Int:
+			value = strconv.FormatInt(field.Int(), 10)
+		}
+
+		names = append(names, v.Type().Field(i).Tag.Get("policy"))
+		values = append(values, value)
+	}
+
+	return
+}
+
+// GetUserPolicy executes vpncmd and gets the security policy of a specific User in a specific Hub.
+// It returns nil if the User has no policy of its own.
+func (s SoftEther) GetUserPolicy(ctx context.Context, id string) (policy *Policy, err error) {
+	return s.getPolicy(ctx, "UserGet", id)
+}
+
+// SetUserPolicy executes vpncmd and updates the security policy of a specific User in a specific Hub.
+// Only the items which differ from the current policy are set; see setPolicy.
+func (s SoftEther) SetUserPolicy(ctx context.Context, id string, policy Policy) (err error) {
+	return s.setPolicy(ctx, "UserGet", "UserPolicySet", id, policy)
+}
+
+// RemoveUserPolicy executes vpncmd and removes the security policy of a specific User in a specific Hub,
+// so that the policy of its Group applies.
+func (s SoftEther) RemoveUserPolicy(ctx context.Context, id string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd UserPolicyRemove [NAME]
+	cmd := s.hubCommand(
+		"UserPolicyRemove",
+		id,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// GetGroupPolicy executes vpncmd and gets the security policy of a specific group in a specific Hub.
+// It returns nil if the group has no policy.
+func (s SoftEther) GetGroupPolicy(ctx context.Context, name string) (policy *Policy, err error) {
+	return s.getPolicy(ctx, "GroupGet", name)
+}
+
+// SetGroupPolicy executes vpncmd and updates the security policy of a specific group in a specific Hub.
+// Only the items which differ from the current policy are set; see setPolicy.
+func (s SoftEther) SetGroupPolicy(ctx context.Context, name string, policy Policy) (err error) {
+	return s.setPolicy(ctx, "GroupGet", "GroupPolicySet", name, policy)
+}
+
+// RemoveGroupPolicy executes vpncmd and removes the security policy of a specific group in a specific Hub.
+func (s SoftEther) RemoveGroupPolicy(ctx context.Context, name string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd GroupPolicyRemove [NAME]
+	cmd := s.hubCommand(
+		"GroupPolicyRemove",
+		name,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// getPolicy reads the security policy of the User or group name out of the output of getCommand.
+func (s SoftEther) getPolicy(ctx context.Context, getCommand, name string) (policy *Policy, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd UserGet|GroupGet [NAME]
+	cmd := s.hubCommand(
+		getCommand,
+		name,
+	)
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	if _, err = parseKeyValue(cmd, cmdOutput); err != nil {
+		return
+	}
+
+	return parsePolicy(cmd, cmdOutput)
+}
+
+// setPolicy sets the items of policy which differ from the current policy of the User or
+// group name, as read by getCommand, with one run of setCommand per item, since vpncmd sets
+// one policy item at a time. If name has no policy yet, all 38 items are set, so up to 39
+// vpncmd runs are made, each preceded by its own certificate check if s.Fingerprint is set.
+// The update is not atomic: it stops at the first failure, and the items set until then keep
+// their new values; read the policy back with getCommand to see which items changed.
+func (s SoftEther) setPolicy(ctx context.Context, getCommand, setCommand, name string, policy Policy) (err error) {
+	current, err := s.getPolicy(ctx, getCommand, name)
+	if err != nil {
+		return
+	}
+
+	names, values := policyChanges(current, policy)
+	for i := range names {
+
+		// Command to execute
+		// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd UserPolicySet|GroupPolicySet [NAME] /NAME:[POLICY_NAME] /VALUE:[VALUE]
+		cmd := s.hubCommand(
+			setCommand,
+			name,
+			"/NAME:"+names[i],
+			"/VALUE:"+values[i],
+		)
+
+		// Execute
+		if _, err = s.execute(ctx, cmd); err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,346 @@
+package softether_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether/softethertest"
+)
+
+// fullPolicyOutput is the output of UserGet for a User with every policy item set.
+const fullPolicyOutput = `Item,Value
+User Name,1
+
+Security Policy Set for this User
+Policy name,Simple description of policy,Setting value
+Access,Allow Access,Enabled
+DHCPFilter,Filter DHCP Packets (IPv4),Enabled
+DHCPNoServer,Disallow DHCP Server Operation (IPv4),Enabled
+DHCPForce,Enforce DHCP Allocated IP Addresses (IPv4),Enabled
+NoBridge,Deny Bridge Operation,Enabled
+NoRouting,Deny Routing Operation (IPv4),Enabled
+CheckMac,Deny MAC Addresses Duplication,Enabled
+CheckIP,Deny IP Address Duplication (IPv4),Enabled
+ArpDhcpOnly,Deny Non-ARP / Non-DHCP / Non-ICMPv6 broadcasts,Enabled
+PrivacyFilter,Privacy Filter Mode,Enabled
+NoServer,Deny Operation as TCP/IP Server (IPv4),Enabled
+NoBroadcastLimiter,Unlimited Number of Broadcasts,Enabled
+MonitorPort,Allow Monitoring Mode,Enabled
+MaxConnection,Maximum Number of TCP Connections,8
+TimeOut,Time-out Period,30 seconds
+MaxMac,Maximum Number of MAC Addresses,2
+MaxIP,Maximum Number of IP Addresses (IPv4),3
+MaxUpload,Upload Bandwidth,"1,000,000 bps"
+MaxDownload,Download Bandwidth,"2,000,000 bps"
+FixPassword,Deny Changing Password,Enabled
+MultiLogins,Maximum Number of Multiple Logins,4
+NoQoS,Deny VoIP / QoS Function,Enabled
+RSandRAFilter,Filter RS / RA Packets (IPv6),Enabled
+RAFilter,Filter RA Packets (IPv6),Enabled
+DHCPv6Filter,Filter DHCP Packets (IPv6),Enabled
+DHCPv6NoServer,Disallow DHCP Server Operation (IPv6),Enabled
+NoRoutingV6,Deny Routing Operation (IPv6),Enabled
+CheckIPv6,Deny IP Address Duplication (IPv6),Enabled
+NoServerV6,Deny Operation as TCP/IP Server (IPv6),Enabled
+MaxIPv6,Maximum Number of IP Addresses (IPv6),5
+NoSavePassword,Disallow Password Save in VPN Client,Enabled
+AutoDisconnect,VPN Client Automatic Disconnect,600 seconds
+FilterIPv4,Filter All IPv4 Packets,Enabled
+FilterIPv6,Filter All IPv6 Packets,Enabled
+FilterNonIP,Filter All Non-IP Packets,Enabled
+NoIPv6DefaultRouterInRA,No Default-Router on IPv6 RA,Enabled
+NoIPv6DefaultRouterInRAWhenIPv6,No Default-Router on IPv6 RA (physical IPv6),Enabled
+VLanId,VLAN ID (IEEE802.1Q),100
+`
+
+// fullPolicy is the policy of fullPolicyOutput.
+var fullPolicy = softether.Policy{
+	Access:                          true,
+	DHCPFilter:                      true,
+	DHCPNoServer:                    true,
+	DHCPForce:                       true,
+	NoBridge:                        true,
+	NoRouting:                       true,
+	CheckMAC:                        true,
+	CheckIP:                         true,
+	ArpDHCPOnly:                     true,
+	PrivacyFilter:                   true,
+	NoServer:                        true,
+	NoBroadcastLimiter:              true,
+	MonitorPort:                     true,
+	MaxConnection:                   8,
+	TimeOut:                         30,
+	MaxMAC:                          2,
+	MaxIP:                           3,
+	MaxUpload:                       1000000,
+	MaxDownload:                     2000000,
+	FixPassword:                     true,
+	MultiLogins:                     4,
+	NoQoS:                           true,
+	RSandRAFilter:                   true,
+	RAFilter:                        true,
+	DHCPv6Filter:                    true,
+	DHCPv6NoServer:                  true,
+	NoRoutingV6:                     true,
+	CheckIPv6:                       true,
+	NoServerV6:                      true,
+	MaxIPv6:                         5,
+	NoSavePassword:                  true,
+	AutoDisconnect:                  600,
+	FilterIPv4:                      true,
+	FilterIPv6:                      true,
+	FilterNonIP:                     true,
+	NoIPv6DefaultRouterInRA:         true,
+	NoIPv6DefaultRouterInRAWhenIPv6: true,
+	VLANID:                          100,
+}
+
+func TestPolicyCommands(t *testing.T) {
+	runCommandTests(t, []commandTest{
+		{
+			name: "GetUserPolicy",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.GetUserPolicy(ctx, "1")
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"UserGet", "1"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "RemoveUserPolicy",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.RemoveUserPolicy(ctx, "1")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"UserPolicyRemove", "1"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "GetGroupPolicy",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.GetGroupPolicy(ctx, "admins")
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"GroupGet", "admins"}},
+			errno: 65, // ERR_GROUP_NOT_FOUND
+		},
+		{
+			name: "SetGroupPolicy",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				policy := softether.DefaultPolicy()
+				policy.MaxUpload, policy.MaxDownload = 10000000, 50000000
+				policy.MaxConnection = 16
+				return s.SetGroupPolicy(ctx, "admins", policy)
+			},
+			hub: "subspace",
+			want: [][]string{
+				{"GroupGet", "admins"},
+				{"GroupPolicySet", "admins", "/NAME:MaxConnection", "/VALUE:16"},
+			},
+			errno: 65, // ERR_GROUP_NOT_FOUND
+		},
+		{
+			name: "RemoveGroupPolicy",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.RemoveGroupPolicy(ctx, "admins")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"GroupPolicyRemove", "admins"}},
+			errno: 65, // ERR_GROUP_NOT_FOUND
+		},
+	})
+}
+
+func TestGetPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		stdout string
+		want   *softether.Policy
+	}{
+		{
+			name: "no policy",
+		},
+		{
+			name:   "every item",
+			stdout: fullPolicyOutput,
+			want:   &fullPolicy,
+		},
+		{
+			name: "disabled and unlimited",
+			stdout: "Item,Value\nUser Name,1\n\nSecurity Policy Set for this User\n" +
+				"Policy name,Simple description of policy,Setting value\n" +
+				"Access,Allow Access,-\nMultiLogins,Maximum Number of Multiple Logins,-\n",
+			want: &softether.Policy{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, runner := newServer()
+			if test.stdout != "" {
+				runner.Handle("UserGet", softethertest.Response{Stdout: test.stdout})
+			}
+
+			policy, err := s.GetUserPolicy(context.Background(), "1")
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if !reflect.DeepEqual(policy, test.want) {
+				t.Errorf("policy = %+v, want %+v", policy, test.want)
+			}
+		})
+	}
+}
+
+func TestGetGroupPolicy(t *testing.T) {
+	s, _ := newServer()
+
+	policy, err := s.GetGroupPolicy(context.Background(), "admins")
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := &softether.Policy{Access: true, MaxConnection: 32, TimeOut: 20, MaxUpload: 10000000, MaxDownload: 50000000}
+	if !reflect.DeepEqual(policy, want) {
+		t.Errorf("policy = %+v, want %+v", policy, want)
+	}
+}
+
+func TestSetUserPolicy(t *testing.T) {
+	set := func(name, value string) []string {
+		return []string{"UserPolicySet", "1", "/NAME:" + name, "/VALUE:" + value}
+	}
+
+	t.Run("no policy", func(t *testing.T) {
+		// Every item is set, by its policy name
+		s, runner := newServer()
+		if err := s.SetUserPolicy(context.Background(), "1", fullPolicy); err != nil {
+			t.Fatalf("err = %v", err)
+		}
+
+		checkCommands(t, runner, "subspace",
+			[]string{"UserGet", "1"},
+			set("Access", "yes"),
+			set("DHCPFilter", "yes"),
+			set("DHCPNoServer", "yes"),
+			set("DHCPForce", "yes"),
+			set("NoBridge", "yes"),
+			set("NoRouting", "yes"),
+			set("CheckMac", "yes"),
+			set("CheckIP", "yes"),
+			set("ArpDhcpOnly", "yes"),
+			set("PrivacyFilter", "yes"),
+			set("NoServer", "yes"),
+			set("NoBroadcastLimiter", "yes"),
+			set("MonitorPort", "yes"),
+			set("MaxConnection", "8"),
+			set("TimeOut", "30"),
+			set("MaxMac", "2"),
+			set("MaxIP", "3"),
+			set("MaxUpload", "1000000"),
+			set("MaxDownload", "2000000"),
+			set("FixPassword", "yes"),
+			set("MultiLogins", "4"),
+			set("NoQoS", "yes"),
+			set("RSandRAFilter", "yes"),
+			set("RAFilter", "yes"),
+			set("DHCPv6Filter", "yes"),
+			set("DHCPv6NoServer", "yes"),
+			set("NoRoutingV6", "yes"),
+			set("CheckIPv6", "yes"),
+			set("NoServerV6", "yes"),
+			set("MaxIPv6", "5"),
+			set("NoSavePassword", "yes"),
+			set("AutoDisconnect", "600"),
+			set("FilterIPv4", "yes"),
+			set("FilterIPv6", "yes"),
+			set("FilterNonIP", "yes"),
+			set("NoIPv6DefaultRouterInRA", "yes"),
+			set("NoIPv6DefaultRouterInRAWhenIPv6", "yes"),
+			set("VLanId", "100"),
+		)
+	})
+
+	t.Run("changes", func(t *testing.T) {
+		// Only the items which differ are set
+		s, runner := newServer()
+		runner.Handle("UserGet", softethertest.Response{Stdout: fullPolicyOutput})
+
+		policy := fullPolicy
+		policy.Access = false
+		policy.MaxConnection = 32
+		policy.VLANID = 0
+		if err := s.SetUserPolicy(context.Background(), "1", policy); err != nil {
+			t.Fatalf("err = %v", err)
+		}
+
+		checkCommands(t, runner, "subspace",
+			[]string{"UserGet", "1"},
+			set("Access", "no"),
+			set("MaxConnection", "32"),
+			set("VLanId", "0"),
+		)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		s, runner := newServer()
+		runner.Handle("UserGet", softethertest.Response{Stdout: fullPolicyOutput})
+
+		if err := s.SetUserPolicy(context.Background(), "1", fullPolicy); err != nil {
+			t.Fatalf("err = %v", err)
+		}
+		checkCommands(t, runner, "subspace", []string{"UserGet", "1"})
+	})
+
+	t.Run("get failure", func(t *testing.T) {
+		s, runner := newServer()
+		runner.Fail("UserGet", 29)
+
+		checkError(t, s.SetUserPolicy(context.Background(), "1", fullPolicy), 29)
+		checkCommands(t, runner, "subspace", []string{"UserGet", "1"})
+	})
+
+	t.Run("set failure", func(t *testing.T) {
+		// The items set before the failure stay set, the ones after it are not tried
+		runner := &failingRunner{Runner: softethertest.NewRunner(), name: "UserPolicySet", after: 2, errno: 38}
+		runner.Handle("UserGet", softethertest.Response{Stdout: fullPolicyOutput})
+		s := softether.SoftEther{IP: "10.0.0.1", Password: "subspace", Hub: "subspace", Runner: runner}
+
+		policy := fullPolicy
+		policy.Access = false
+		policy.MaxConnection = 32
+		policy.MultiLogins = -1
+		policy.VLANID = 0
+		checkError(t, s.SetUserPolicy(context.Background(), "1", policy), 38)
+
+		checkCommands(t, runner.Runner, "subspace",
+			[]string{"UserGet", "1"},
+			set("Access", "no"),
+			set("MaxConnection", "32"),
+			set("MultiLogins", "-1"),
+		)
+	})
+}
+
+// failingRunner is a softethertest.Runner on which the vpncmd command name fails with errno
+// once it has run after times.
+type failingRunner struct {
+	*softethertest.Runner
+	name  string
+	after int
+	errno int
+}
+
+func (r *failingRunner) Run(ctx context.Context, cmd softether.Command) (stdout, stderr []byte, exitCode int, err error) {
+	if cmd.Name == r.name {
+		if r.after == 0 {
+			r.Fail(r.name, r.errno)
+		}
+		r.after--
+	}
+	return r.Runner.Run(ctx, cmd)
+}
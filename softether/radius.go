@@ -0,0 +1,90 @@
+package softether
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+)
+
+// DefaultRadiusPort is the port SetRadiusServer uses when RadiusServer.Port is 0.
+const DefaultRadiusPort = 1812
+
+// RadiusServer is the RADIUS server a Virtual Hub authenticates users with RADIUS
+// authentication against.
+type RadiusServer struct {
+	Enabled       bool // Only filled in by GetRadiusServer
+	Host          string
+	Port          int
+	Secret        string        // Shared secret; only used by SetRadiusServer, vpncmd does not show it
+	RetryInterval time.Duration // Rounded to milliseconds
+}
+
+// parseRadiusServer converts the output table of RadiusServerGet. Unlike the others, the
+// host name label ends with a colon.
+func parseRadiusServer(m map[string]string) RadiusServer {
+	return RadiusServer{
+		Enabled:       parseBool(m["Use RADIUS Server"]),
+		Host:          parseString(m["RADIUS Server Host Name or IP Address:"]),
+		Port:          parseInt(m["RADIUS Server Port Number"]),
+		RetryInterval: time.Duration(parseCount(m["Retry Interval (in milliseconds)"])) * time.Millisecond,
+	}
+}
+
+// GetRadiusServer executes vpncmd and gets the RADIUS server of a specific Hub.
+// Enabled is false if the Hub has no RADIUS server.
+func (s SoftEther) GetRadiusServer(ctx context.Context) (server RadiusServer, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd RadiusServerGet
+	cmd := s.hubCommand("RadiusServerGet")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	serverInfo, err := parseKeyValue(cmd, cmdOutput)
+	if err != nil {
+		return
+	}
+
+	server = parseRadiusServer(serverInfo)
+	return
+}
+
+// SetRadiusServer executes vpncmd and sets the RADIUS server of a specific Hub.
+func (s SoftEther) SetRadiusServer(ctx context.Context, server RadiusServer) (err error) {
+	port := server.Port
+	if port == 0 {
+		port = DefaultRadiusPort
+	}
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /in:[SCRIPT], SCRIPT: RadiusServerSet [HOST]:[PORT] /SECRET:[SECRET] /RETRY_INTERVAL:[MILLISECONDS]
+	cmd := s.hubCommand(
+		"RadiusServerSet",
+		net.JoinHostPort(server.Host, strconv.Itoa(port)),
+		"/SECRET:"+server.Secret,
+		"/RETRY_INTERVAL:"+strconv.FormatInt(int64(server.RetryInterval/time.Millisecond), 10),
+	)
+	cmd.Secrets = []int{1}
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// DeleteRadiusServer executes vpncmd and removes the RADIUS server of a specific Hub,
+// so that users with RADIUS authentication can no longer log in.
+func (s SoftEther) DeleteRadiusServer(ctx context.Context) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd RadiusServerDelete
+	cmd := s.hubCommand("RadiusServerDelete")
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
@@ -0,0 +1,111 @@
+package softether_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether/softethertest"
+)
+
+func TestRadiusCommands(t *testing.T) {
+	runCommandTests(t, []commandTest{
+		{
+			name: "GetRadiusServer",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.GetRadiusServer(ctx)
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"RadiusServerGet"}},
+			errno: 8, // ERR_HUB_NOT_FOUND
+		},
+		{
+			name: "SetRadiusServer",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetRadiusServer(ctx, softether.RadiusServer{
+					Host:          "radius.example.com",
+					Port:          11812,
+					Secret:        "radius-secret",
+					RetryInterval: 1500*time.Millisecond + 300*time.Microsecond,
+				})
+			},
+			hub:     "subspace",
+			want:    [][]string{{"RadiusServerSet", "radius.example.com:11812", "/SECRET:radius-secret", "/RETRY_INTERVAL:1500"}},
+			secrets: []string{"radius-secret"},
+			errno:   38, // ERR_INVALID_PARAMETER
+		},
+		{
+			name: "SetRadiusServer default port",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetRadiusServer(ctx, softether.RadiusServer{Host: "2001:db8::53", Secret: "radius-secret"})
+			},
+			hub:     "subspace",
+			want:    [][]string{{"RadiusServerSet", "[2001:db8::53]:1812", "/SECRET:radius-secret", "/RETRY_INTERVAL:0"}},
+			secrets: []string{"radius-secret"},
+			errno:   38, // ERR_INVALID_PARAMETER
+		},
+		{
+			name: "DeleteRadiusServer",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.DeleteRadiusServer(ctx)
+			},
+			hub:   "subspace",
+			want:  [][]string{{"RadiusServerDelete"}},
+			errno: 8, // ERR_HUB_NOT_FOUND
+		},
+	})
+}
+
+func TestSetRadiusServerRedacted(t *testing.T) {
+	s, runner := newServer()
+	if err := s.SetRadiusServer(context.Background(), softether.RadiusServer{Host: "radius.example.com", Secret: "radius-secret"}); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	line := runner.Commands()[0].String()
+	if !strings.Contains(line, "RadiusServerSet radius.example.com:1812 /SECRET:") || strings.Contains(line, "radius-secret") {
+		t.Errorf("String() = %s", line)
+	}
+}
+
+func TestGetRadiusServer(t *testing.T) {
+	tests := []struct {
+		name   string
+		stdout string
+		want   softether.RadiusServer
+	}{
+		{
+			name: "fixture",
+			want: softether.RadiusServer{
+				Enabled:       true,
+				Host:          "radius.example.com",
+				Port:          1812,
+				RetryInterval: 500 * time.Millisecond,
+			},
+		},
+		{
+			name:   "no server",
+			stdout: "Item,Value\nUse RADIUS Server,No\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, runner := newServer()
+			if test.stdout != "" {
+				runner.Handle("RadiusServerGet", softethertest.Response{Stdout: test.stdout})
+			}
+
+			server, err := s.GetRadiusServer(context.Background())
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if server != test.want {
+				t.Errorf("server = %+v, want %+v", server, test.want)
+			}
+		})
+	}
+}
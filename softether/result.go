@@ -0,0 +1,69 @@
+package softether
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+var reErrorMessage = regexp.MustCompile(`Error occurred\. \(Error code: [0-9]+\)\r?\n(.+)`)
+
+// checkResult converts the result of running cmd into an *Error, or nil if cmd
+// succeeded. runErr is the error returned by the Runner, if vpncmd did not exit normally.
+// Errors of commands whose ctx is done wrap ctx.Err().
+func checkResult(ctx context.Context, cmd Command, stdout, stderr []byte, exitCode int, runErr error) error {
+	var exitErr *exec.ExitError
+
+	switch {
+	case runErr == nil && exitCode == 0:
+		return nil
+
+	case ctx.Err() == context.DeadlineExceeded:
+		return NewKindError(KindTimeout, cmd.Name, ctx.Err())
+
+	case ctx.Err() != nil:
+		return NewKindError(KindCanceled, cmd.Name, ctx.Err())
+
+	case runErr == nil:
+		cmdError := NewError(exitCode)
+		cmdError.Command = cmd.Name
+		cmdError.Stderr = string(stderr)
+
+		// Prefer the message printed by vpncmd, e.g. "The specified object could not be found."
+		if match := reErrorMessage.FindSubmatch(stdout); match != nil {
+			cmdError.Message = strings.TrimSpace(string(match[1]))
+		}
+		return cmdError
+
+	case errors.Is(runErr, exec.ErrNotFound), errors.Is(runErr, os.ErrNotExist):
+		return NewKindError(KindNotFound, cmd.Name, runErr)
+
+	case errors.As(runErr, &exitErr):
+		cmdError := NewKindError(KindSignal, cmd.Name, runErr)
+		cmdError.Stderr = string(stderr)
+		return cmdError
+
+	default:
+		cmdError := NewKindError(KindExec, cmd.Name, runErr)
+		cmdError.Stderr = string(stderr)
+		return cmdError
+	}
+}
+
+// parseFailure returns a KindParse *Error for the output of cmd.
+func parseFailure(cmd Command, format string, args ...interface{}) error {
+	return NewKindError(KindParse, cmd.Name, fmt.Errorf(format, args...))
+}
+
+// invalidParameter returns an ERR_INVALID_PARAMETER *Error for arguments of command
+// which are rejected before vpncmd is run.
+func invalidParameter(command string, err error) error {
+	cmdError := NewError(38)
+	cmdError.Command = command
+	cmdError.Err = err
+	return cmdError
+}
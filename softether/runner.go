@@ -0,0 +1,185 @@
+package softether
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// redacted replaces secrets in logged commands.
+const redacted = "********"
+
+// Command describes a single vpncmd invocation against a SoftEther server.
+type Command struct {
+	Server   string   // Address of the server, e.g. "10.0.0.1:992"
+	Password string   // Administrator password
+	Hub      string   // Virtual Hub to manage; empty for server-wide commands
+	Name     string   // vpncmd command, e.g. "SessionList"
+	Args     []string // Arguments passed to the vpncmd command
+	Secrets  []int    // Indexes of the Args which hold values that must not be exposed, e.g. user passwords
+}
+
+// Argv returns the arguments the vpncmd binary should be executed with to run c.
+// The administrator password is not part of them; vpncmd prompts for it.
+func (c Command) Argv() []string {
+	argv := c.connectArgv()
+	argv = append(argv, "/cmd", c.Name)
+	return append(argv, c.Args...)
+}
+
+// connectArgv returns the arguments which select the server and Hub of c, and
+// make vpncmd print its output as CSV.
+func (c Command) connectArgv() []string {
+	argv := []string{
+		"/server",
+		c.Server,
+	}
+	if c.Hub != "" {
+		argv = append(argv, "/hub:"+c.Hub)
+	}
+	return append(argv, "/csv")
+}
+
+// Line returns c as a line of a vpncmd /IN script.
+func (c Command) Line() string {
+	line := []string{c.Name}
+	for _, arg := range c.Args {
+		if strings.ContainsAny(arg, " \t\"") {
+			arg = `"` + strings.Replace(arg, `"`, `""`, -1) + `"`
+		}
+		line = append(line, arg)
+	}
+	return strings.Join(line, " ")
+}
+
+// checkLine fails with ERR_INVALID_PARAMETER if an argument of c holds a line break.
+// vpncmd reads its /IN script line by line, so the rest of the argument would run as
+// another command.
+func (c Command) checkLine() error {
+	for _, arg := range c.Args {
+		if strings.ContainsAny(arg, "\r\n") {
+			return invalidParameter(c.Name, errors.New("line break in argument"))
+		}
+	}
+	return nil
+}
+
+// String returns the vpncmd command line of c with its Secrets redacted, for logging.
+// Only the value of a "/NAME:value" parameter is redacted, so that its name stays readable.
+func (c Command) String() string {
+	argv := c.connectArgv()
+	argv = append(argv, "/cmd", c.Name)
+	for i, arg := range c.Args {
+		if c.isSecret(i) {
+			name := ""
+			if j := strings.Index(arg, ":"); strings.HasPrefix(arg, "/") && j >= 0 {
+				name = arg[:j+1]
+			}
+			arg = name + redacted
+		}
+		argv = append(argv, arg)
+	}
+	return "vpncmd " + strings.Join(argv, " ")
+}
+
+// isSecret reports whether the argument of c at index i is one of its Secrets.
+func (c Command) isSecret(i int) bool {
+	for _, secret := range c.Secrets {
+		if secret == i {
+			return true
+		}
+	}
+	return false
+}
+
+// Runner executes vpncmd commands. Implementations return the standard output,
+// in the CSV format of vpncmd /CSV, standard error and exit code of the command. The exit code of vpncmd is the
+// SoftEther error code, see Strerror. Implementations must abort the command
+// when ctx is done, and must not expose the Password and Secrets of the command
+// to other users of the machine.
+//
+// Certificates, keys and configuration files are exchanged with vpncmd through
+// temporary files, so the methods of SoftEther which do so only work with a Runner
+// which executes vpncmd on the local machine, such as LocalRunner.
+type Runner interface {
+	Run(ctx context.Context, cmd Command) (stdout, stderr []byte, exitCode int, err error)
+}
+
+// LocalRunner is the default Runner. It executes the vpncmd binary on the local machine.
+//
+// The administrator password is written to the standard input of vpncmd, which
+// prompts for it. Commands with Secrets are written to a temporary script, readable
+// only by the current user, and run with /IN, so that no credentials show up in
+// the process list.
+type LocalRunner struct {
+	// Path of the vpncmd binary. Defaults to "vpncmd", looked up in PATH.
+	Path string
+}
+
+// Run executes cmd with the local vpncmd binary. A non-zero exit code is not an
+// error; err is only set (with an exit code of -1) when vpncmd could not be run
+// or did not exit normally, e.g. because it was killed by a signal or ctx was done.
+func (r LocalRunner) Run(ctx context.Context, cmd Command) (stdout, stderr []byte, exitCode int, err error) {
+	path := r.Path
+	if path == "" {
+		path = "vpncmd"
+	}
+
+	argv := cmd.Argv()
+	if len(cmd.Secrets) > 0 {
+		script, err := writeScript(cmd.Line())
+		if err != nil {
+			return nil, nil, -1, err
+		}
+		defer os.Remove(script)
+
+		argv = append(cmd.connectArgv(), "/in:"+script)
+	}
+
+	c := exec.CommandContext(ctx, path, argv...)
+	cmdOutput := &bytes.Buffer{} // Stdout buffer
+	cmdError := &bytes.Buffer{}  // Stderr buffer
+
+	// Attach buffers to command output, answer the password prompt and execute
+	c.Stdin = strings.NewReader(cmd.Password + "\n")
+	c.Stdout = cmdOutput
+	c.Stderr = cmdError
+	err = c.Run() // will wait for command to return
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() >= 0 {
+		return cmdOutput.Bytes(), cmdError.Bytes(), exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return cmdOutput.Bytes(), cmdError.Bytes(), -1, err
+	}
+
+	return cmdOutput.Bytes(), cmdError.Bytes(), 0, nil
+}
+
+// writeScript writes a vpncmd /IN script to a new temporary file, which only the
+// current user can read, and returns its path.
+func writeScript(line string) (path string, err error) {
+	return writeTempFile("vpncmd-*.txt", []byte(line+"\n"))
+}
+
+// writeTempFile writes data to a new temporary file named after pattern, which only
+// the current user can read, and returns its path.
+func writeTempFile(pattern string, data []byte) (path string, err error) {
+	f, err := os.CreateTemp("", pattern) // created with mode 0600
+	if err != nil {
+		return "", err
+	}
+
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
@@ -0,0 +1,225 @@
+package softether_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+)
+
+// stubVpncmd writes a shell script standing in for vpncmd to dir, which runs body.
+// It skips t where there is no POSIX shell.
+func stubVpncmd(t *testing.T, dir, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the vpncmd stub needs a POSIX shell")
+	}
+
+	path := filepath.Join(dir, "vpncmd")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// readFile returns the contents of the file name in dir, or fails t.
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestLocalRunner(t *testing.T) {
+	dir := t.TempDir()
+
+	// Record stdin and argv, and copy the /IN script with its mode, before it is removed
+	path := stubVpncmd(t, dir, `
+read password
+printf '%s' "$password" > `+dir+`/stdin
+printf '%s\n' "$@" > `+dir+`/argv
+for arg in "$@"; do
+	case "$arg" in
+	/in:*)
+		script="${arg#/in:}"
+		cp "$script" `+dir+`/script
+		find "$script" -perm 0600 > `+dir+`/mode
+		;;
+	esac
+done
+echo "Item,Value"
+echo "Server Type,Standalone Server"
+`)
+
+	s := softether.SoftEther{IP: "10.0.0.1", Password: "admin-pass", Hub: "subspace", Runner: softether.LocalRunner{Path: path}}
+	ctx := context.Background()
+
+	t.Run("password on stdin", func(t *testing.T) {
+		status, err := s.GetServerStatus(ctx)
+		if err != nil {
+			t.Fatalf("err = %v", err)
+		}
+		if status.ServerType != "Standalone Server" {
+			t.Errorf("ServerType = %q", status.ServerType)
+		}
+
+		if stdin := readFile(t, dir, "stdin"); stdin != "admin-pass" {
+			t.Errorf("stdin = %q, want the password", stdin)
+		}
+		argv := readFile(t, dir, "argv")
+		if argv != "/server\n10.0.0.1:992\n/csv\n/cmd\nServerStatusGet\n" {
+			t.Errorf("argv = %q", argv)
+		}
+		if strings.Contains(argv, "admin-pass") {
+			t.Errorf("argv %q exposes the password", argv)
+		}
+	})
+
+	t.Run("secrets in /IN script", func(t *testing.T) {
+		if err := s.SetUserPassword(ctx, "1", "user pass"); err != nil {
+			t.Fatalf("err = %v", err)
+		}
+
+		argv := readFile(t, dir, "argv")
+		if strings.Contains(argv, "user pass") || strings.Contains(argv, "admin-pass") {
+			t.Errorf("argv %q exposes a secret", argv)
+		}
+		if !strings.HasPrefix(argv, "/server\n10.0.0.1:992\n/hub:subspace\n/csv\n/in:") {
+			t.Errorf("argv = %q, want an /IN script", argv)
+		}
+
+		if script := readFile(t, dir, "script"); script != "UserPasswordSet 1 \"/PASSWORD:user pass\"\n" {
+			t.Errorf("script = %q", script)
+		}
+		if mode := readFile(t, dir, "mode"); mode == "" {
+			t.Error("script is readable by other users, want mode 0600")
+		}
+
+		script := strings.TrimPrefix(strings.Split(argv, "\n")[4], "/in:")
+		if _, err := os.Stat(script); !os.IsNotExist(err) {
+			t.Errorf("script %s not removed: %v", script, err)
+		}
+	})
+}
+
+func TestLocalRunnerFailures(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		body    string // Script run as vpncmd; none for a missing binary
+		timeout time.Duration
+		kind    softether.Kind
+	}{
+		{
+			name: "vpncmd not found",
+			kind: softether.KindNotFound,
+		},
+		{
+			name: "killed by signal",
+			body: "kill -KILL $$",
+			kind: softether.KindSignal,
+		},
+		{
+			name:    "timeout",
+			body:    "exec sleep 10",
+			timeout: 100 * time.Millisecond,
+			kind:    softether.KindTimeout,
+		},
+		{
+			name: "error code",
+			body: "echo 'Error occurred. (Error code: 29)'; echo 'The object could not be found.'; exit 29",
+			kind: softether.KindErrorCode,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "vpncmd")
+			if test.body != "" {
+				path = stubVpncmd(t, dir, test.body)
+			}
+
+			s := softether.SoftEther{IP: "10.0.0.1", Hub: "subspace", Timeout: test.timeout, Runner: softether.LocalRunner{Path: path}}
+			_, err := s.GetUserList(ctx)
+			checkKind(t, err, test.kind)
+		})
+	}
+}
+
+func TestCommandString(t *testing.T) {
+	tests := []struct {
+		cmd  softether.Command
+		want string
+	}{
+		{
+			cmd: softether.Command{
+				Server:   "10.0.0.1:992",
+				Password: "admin-pass",
+				Hub:      "subspace",
+				Name:     "UserPasswordSet",
+				Args:     []string{"1", "/PASSWORD:s3cret"},
+				Secrets:  []int{1},
+			},
+			want: "vpncmd /server 10.0.0.1:992 /hub:subspace /csv /cmd UserPasswordSet 1 /PASSWORD:********",
+		},
+		{
+			// Other arguments holding the secret are left alone
+			cmd: softether.Command{
+				Server:  "10.0.0.1:992",
+				Hub:     "vpn",
+				Name:    "UserPasswordSet",
+				Args:    []string{"vpn", "/PASSWORD:vpn"},
+				Secrets: []int{1},
+			},
+			want: "vpncmd /server 10.0.0.1:992 /hub:vpn /csv /cmd UserPasswordSet vpn /PASSWORD:********",
+		},
+		{
+			// Positional secrets are redacted as a whole, and so are empty ones
+			cmd: softether.Command{
+				Server:  "10.0.0.1:992",
+				Name:    "RadiusServerSet",
+				Args:    []string{"radius.example.com:1812", "/SECRET:", "/RETRY_INTERVAL:500"},
+				Secrets: []int{0, 1},
+			},
+			want: "vpncmd /server 10.0.0.1:992 /csv /cmd RadiusServerSet ******** /SECRET:******** /RETRY_INTERVAL:500",
+		},
+		{
+			cmd: softether.Command{
+				Server: "[2001:db8::1]:443",
+				Name:   "ServerStatusGet",
+			},
+			want: "vpncmd /server [2001:db8::1]:443 /csv /cmd ServerStatusGet",
+		},
+	}
+
+	for _, test := range tests {
+		if got := test.cmd.String(); got != test.want {
+			t.Errorf("String() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestLineBreakRejected(t *testing.T) {
+	// A line break would run the rest of the argument as another command of the /IN script
+	for _, password := range []string{"pass\nServerPasswordSet owned", "pass\rHubDelete subspace"} {
+		s, runner := newServer()
+		err := s.SetUserPassword(context.Background(), "1", password)
+		checkError(t, err, 38)
+		if !errors.Is(err, softether.ErrInvalidParameter) || strings.Contains(err.Error(), "owned") {
+			t.Errorf("err = %v, want ErrInvalidParameter without the argument", err)
+		}
+		if len(runner.Commands()) != 0 {
+			t.Errorf("commands = %v, want none", runner.Commands())
+		}
+	}
+}
@@ -0,0 +1,461 @@
+package softether
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SecureNATStatus is the current status of the SecureNAT function of a Virtual Hub.
+type SecureNATStatus struct {
+	Hub           string
+	TCPSessions   int
+	UDPSessions   int
+	ICMPSessions  int
+	DNSSessions   int
+	DHCPClients   int
+	KernelModeNAT bool
+	RawIPModeNAT  bool
+}
+
+// VirtualHost is the network interface SecureNAT uses on the Virtual Hub.
+type VirtualHost struct {
+	MAC  string
+	IP   string
+	Mask string
+}
+
+// NATOptions are the settings of the virtual NAT of SecureNAT.
+type NATOptions struct {
+	Enabled    bool
+	MTU        int
+	TCPTimeout time.Duration // Rounded to seconds
+	UDPTimeout time.Duration // Rounded to seconds
+	Log        bool          // Save NAT and DHCP operation logs
+}
+
+// StaticRoute is a route the virtual DHCP server pushes to its clients.
+type StaticRoute struct {
+	Network string
+	Mask    string
+	Gateway string
+}
+
+// DHCPOptions are the settings of the virtual DHCP server of SecureNAT.
+// Empty Gateway, DNS and DNS2 addresses are not pushed to clients.
+type DHCPOptions struct {
+	Enabled      bool
+	Start        string // First address of the pool
+	End          string // Last address of the pool
+	Mask         string
+	LeaseTime    time.Duration // Rounded to seconds
+	Gateway      string
+	DNS          string
+	DNS2         string
+	Domain       string
+	Log          bool // Save NAT and DHCP operation logs
+	StaticRoutes []StaticRoute
+}
+
+// NATEntry is a session of the virtual NAT, as listed by ListNATTable.
+type NATEntry struct {
+	ID                int
+	Protocol          string // "TCP/IP", "UDP/IP", "DNS" or "ICMP"
+	SrcHost           string
+	SrcPort           int
+	DestHost          string
+	DestPort          int
+	CreatedAt         time.Time
+	LastCommunication time.Time
+	ReceivedBytes     int64
+	SentBytes         int64
+	TCPStatus         string // e.g. "Running"; empty for other protocols
+}
+
+// DHCPLease is an address leased by the virtual DHCP server, as listed by ListDHCPLeases.
+type DHCPLease struct {
+	ID        int
+	LeasedAt  time.Time
+	ExpiresAt time.Time
+	MAC       string
+	IP        string
+	HostName  string
+}
+
+// parseSecureNATStatus converts the output table of SecureNatStatusGet.
+func parseSecureNATStatus(m map[string]string) SecureNATStatus {
+	return SecureNATStatus{
+		Hub:           parseString(m["Virtual Hub Name"]),
+		TCPSessions:   int(parseCount(m["NAT TCP/IP Sessions"])),
+		UDPSessions:   int(parseCount(m["NAT UDP/IP Sessions"])),
+		ICMPSessions:  int(parseCount(m["NAT ICMP Sessions"])),
+		DNSSessions:   int(parseCount(m["NAT DNS Sessions"])),
+		DHCPClients:   int(parseCount(m["Allocated DHCP Clients"])),
+		KernelModeNAT: parseBool(m["Kernel-mode NAT is Active"]),
+		RawIPModeNAT:  parseBool(m["Raw IP mode NAT is Active"]),
+	}
+}
+
+// parseVirtualHost converts the output table of SecureNatHostGet.
+func parseVirtualHost(m map[string]string) VirtualHost {
+	return VirtualHost{
+		MAC:  parseString(m["MAC Address"]),
+		IP:   parseString(m["IP Address"]),
+		Mask: parseString(m["Subnet Mask"]),
+	}
+}
+
+// parseNATOptions converts the output table of NatGet.
+func parseNATOptions(m map[string]string) NATOptions {
+	return NATOptions{
+		Enabled:    parseBool(m["Use Virtual NAT Function"]),
+		MTU:        int(parseCount(m["MTU Value"])),
+		TCPTimeout: time.Duration(parseCount(m["TCP Session Timeout (Seconds)"])) * time.Second,
+		UDPTimeout: time.Duration(parseCount(m["UDP Session Timeout (Seconds)"])) * time.Second,
+		Log:        parseBool(m["Save NAT and DHCP Operation Log"]),
+	}
+}
+
+// parseDHCPOptions converts the output table of DhcpGet.
+func parseDHCPOptions(m map[string]string) DHCPOptions {
+	return DHCPOptions{
+		Enabled:      parseBool(m["Use Virtual DHCP Function"]),
+		Start:        parseString(m["Start Distribution Address Band"]),
+		End:          parseString(m["End Distribution Address Band"]),
+		Mask:         parseString(m["Subnet Mask"]),
+		LeaseTime:    time.Duration(parseCount(m["Lease Limit (Seconds)"])) * time.Second,
+		Gateway:      parseAddress(m["Default Gateway Address"]),
+		DNS:          parseAddress(m["DNS Server Address 1"]),
+		DNS2:         parseAddress(m["DNS Server Address 2"]),
+		Domain:       parseString(m["Domain Name"]),
+		Log:          parseBool(m["Record Log of NAT and DHCP Operation"]),
+		StaticRoutes: parseStaticRoutes(m["Static Routing Table to Push"]),
+	}
+}
+
+// parseAddress converts optional IP addresses, where "None" and "0.0.0.0" mean empty.
+func parseAddress(value string) string {
+	value = parseString(value)
+	if value == "None" || value == "0.0.0.0" {
+		return ""
+	}
+	return value
+}
+
+// parseStaticRoutes converts a list of routes such as
+// "192.168.5.0/255.255.255.0/192.168.4.254, 10.0.0.0/255.0.0.0/192.168.4.253".
+func parseStaticRoutes(value string) (routes []StaticRoute) {
+	for _, route := range strings.Split(parseString(value), ",") {
+		parts := strings.Split(strings.TrimSpace(route), "/")
+		if len(parts) != 3 {
+			continue
+		}
+		routes = append(routes, StaticRoute{Network: parts[0], Mask: parts[1], Gateway: parts[2]})
+	}
+	return
+}
+
+// formatStaticRoutes converts routes to the /PUSHROUTE parameter of DhcpSet.
+func formatStaticRoutes(routes []StaticRoute) string {
+	list := make([]string, 0, len(routes))
+	for _, route := range routes {
+		list = append(list, route.Network+"/"+route.Mask+"/"+route.Gateway)
+	}
+	return strings.Join(list, ",")
+}
+
+// formatAddress converts optional IP addresses to the "none" vpncmd expects for empty ones.
+func formatAddress(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+// formatSeconds converts a duration to whole seconds.
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Second), 10)
+}
+
+// parseNATEntry converts a row of NatTable.
+func parseNATEntry(m map[string]string) NATEntry {
+	entry := NATEntry{
+		ID:                parseInt(m["ID"]),
+		Protocol:          parseString(m["Protocol"]),
+		SrcHost:           parseString(m["Source Host"]),
+		SrcPort:           parseInt(m["Source Port"]),
+		DestHost:          parseString(m["Destination Host"]),
+		DestPort:          parseInt(m["Destination Port"]),
+		CreatedAt:         parseTime(m["Session Created On"]),
+		LastCommunication: parseTime(m["Last Communication Time"]),
+		TCPStatus:         parseString(m["TCP Connection Status"]),
+	}
+
+	// Both directions share a column, e.g. "678,901 / 12,345"
+	if size := m["Receive / Send Size"]; strings.Contains(size, "/") {
+		i := strings.Index(size, "/")
+		entry.ReceivedBytes = parseCount(size[:i])
+		entry.SentBytes = parseCount(size[i+1:])
+	}
+
+	return entry
+}
+
+// parseDHCPLease converts a row of DhcpTable.
+func parseDHCPLease(m map[string]string) DHCPLease {
+	return DHCPLease{
+		ID:        parseInt(m["ID"]),
+		LeasedAt:  parseTime(m["Leased at"]),
+		ExpiresAt: parseTime(m["Expires at"]),
+		MAC:       parseString(m["MAC Address"]),
+		IP:        parseString(m["Allocated IP"]),
+		HostName:  parseString(m["Client Host Name"]),
+	}
+}
+
+// EnableSecureNAT executes vpncmd and enables SecureNAT on a specific Hub.
+func (s SoftEther) EnableSecureNAT(ctx context.Context) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd SecureNatEnable
+	cmd := s.hubCommand("SecureNatEnable")
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// DisableSecureNAT executes vpncmd and disables SecureNAT on a specific Hub.
+func (s SoftEther) DisableSecureNAT(ctx context.Context) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd SecureNatDisable
+	cmd := s.hubCommand("SecureNatDisable")
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// GetSecureNATStatus executes vpncmd and gets the status of SecureNAT on a specific Hub.
+func (s SoftEther) GetSecureNATStatus(ctx context.Context) (status SecureNATStatus, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd SecureNatStatusGet
+	cmd := s.hubCommand("SecureNatStatusGet")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	statusMap, err := parseKeyValue(cmd, cmdOutput)
+	if err != nil {
+		return
+	}
+
+	status = parseSecureNATStatus(statusMap)
+	return
+}
+
+// GetVirtualHost executes vpncmd and gets the SecureNAT virtual host interface of a specific Hub.
+func (s SoftEther) GetVirtualHost(ctx context.Context) (host VirtualHost, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd SecureNatHostGet
+	cmd := s.hubCommand("SecureNatHostGet")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	hostInfo, err := parseKeyValue(cmd, cmdOutput)
+	if err != nil {
+		return
+	}
+
+	host = parseVirtualHost(hostInfo)
+	return
+}
+
+// SetVirtualHost executes vpncmd and updates the SecureNAT virtual host interface of a specific Hub.
+func (s SoftEther) SetVirtualHost(ctx context.Context, host VirtualHost) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd SecureNatHostSet /MAC:[MAC] /IP:[IP] /MASK:[MASK]
+	cmd := s.hubCommand(
+		"SecureNatHostSet",
+		"/MAC:"+host.MAC,
+		"/IP:"+host.IP,
+		"/MASK:"+host.Mask,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// GetNATOptions executes vpncmd and gets the SecureNAT virtual NAT settings of a specific Hub.
+func (s SoftEther) GetNATOptions(ctx context.Context) (options NATOptions, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd NatGet
+	cmd := s.hubCommand("NatGet")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	natInfo, err := parseKeyValue(cmd, cmdOutput)
+	if err != nil {
+		return
+	}
+
+	options = parseNATOptions(natInfo)
+	return
+}
+
+// SetNATOptions executes vpncmd and updates the SecureNAT virtual NAT settings of a specific Hub,
+// then enables or disables the virtual NAT. These are two vpncmd commands: if the second one fails,
+// the settings are saved but the virtual NAT keeps its previous state.
+func (s SoftEther) SetNATOptions(ctx context.Context, options NATOptions) (err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd NatSet /MTU:[MTU] /TCPTIMEOUT:[SECONDS] /UDPTIMEOUT:[SECONDS] /LOG:[yes|no]
+	cmd := s.hubCommand(
+		"NatSet",
+		"/MTU:"+strconv.Itoa(options.MTU),
+		"/TCPTIMEOUT:"+formatSeconds(options.TCPTimeout),
+		"/UDPTIMEOUT:"+formatSeconds(options.UDPTimeout),
+		"/LOG:"+formatBool(options.Log),
+	)
+
+	// Execute
+	if _, err = s.execute(ctx, cmd); err != nil {
+		return
+	}
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd NatEnable|NatDisable
+	command := "NatDisable"
+	if options.Enabled {
+		command = "NatEnable"
+	}
+	cmd = s.hubCommand(command)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// GetDHCPOptions executes vpncmd and gets the SecureNAT virtual DHCP server settings of a specific Hub.
+func (s SoftEther) GetDHCPOptions(ctx context.Context) (options DHCPOptions, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd DhcpGet
+	cmd := s.hubCommand("DhcpGet")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	dhcpInfo, err := parseKeyValue(cmd, cmdOutput)
+	if err != nil {
+		return
+	}
+
+	options = parseDHCPOptions(dhcpInfo)
+	return
+}
+
+// SetDHCPOptions executes vpncmd and updates the SecureNAT virtual DHCP server settings of a specific Hub,
+// then enables or disables the virtual DHCP server. These are two vpncmd commands: if the second one fails,
+// the settings are saved but the virtual DHCP server keeps its previous state.
+func (s SoftEther) SetDHCPOptions(ctx context.Context, options DHCPOptions) (err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd DhcpSet /START:[IP] /END:[IP] /MASK:[MASK] /EXPIRE:[SECONDS] /GW:[IP] /DNS:[IP] /DNS2:[IP] /DOMAIN:[DOMAIN] /LOG:[yes|no] /PUSHROUTE:[NETWORK/MASK/GATEWAY,...]
+	cmd := s.hubCommand(
+		"DhcpSet",
+		"/START:"+options.Start,
+		"/END:"+options.End,
+		"/MASK:"+options.Mask,
+		"/EXPIRE:"+formatSeconds(options.LeaseTime),
+		"/GW:"+formatAddress(options.Gateway),
+		"/DNS:"+formatAddress(options.DNS),
+		"/DNS2:"+formatAddress(options.DNS2),
+		"/DOMAIN:"+options.Domain,
+		"/LOG:"+formatBool(options.Log),
+		"/PUSHROUTE:"+formatStaticRoutes(options.StaticRoutes),
+	)
+
+	// Execute
+	if _, err = s.execute(ctx, cmd); err != nil {
+		return
+	}
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd DhcpEnable|DhcpDisable
+	command := "DhcpDisable"
+	if options.Enabled {
+		command = "DhcpEnable"
+	}
+	cmd = s.hubCommand(command)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// ListNATTable executes vpncmd and gets the sessions of the SecureNAT virtual NAT of a specific Hub.
+func (s SoftEther) ListNATTable(ctx context.Context) (entries []NATEntry, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd NatTable
+	cmd := s.hubCommand("NatTable")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	rows, err := parseTable(cmd, cmdOutput, "ID")
+	for _, row := range rows {
+		entries = append(entries, parseNATEntry(row))
+	}
+
+	return
+}
+
+// ListDHCPLeases executes vpncmd and gets the addresses leased by the SecureNAT virtual DHCP server of a specific Hub.
+func (s SoftEther) ListDHCPLeases(ctx context.Context) (leases []DHCPLease, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd DhcpTable
+	cmd := s.hubCommand("DhcpTable")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	rows, err := parseTable(cmd, cmdOutput, "ID")
+	for _, row := range rows {
+		leases = append(leases, parseDHCPLease(row))
+	}
+
+	return
+}
@@ -0,0 +1,390 @@
+package softether_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether/softethertest"
+)
+
+func TestSecureNATCommands(t *testing.T) {
+	runCommandTests(t, []commandTest{
+		{
+			name: "EnableSecureNAT",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.EnableSecureNAT(ctx)
+			},
+			hub:   "subspace",
+			want:  [][]string{{"SecureNatEnable"}},
+			errno: 8, // ERR_HUB_NOT_FOUND
+		},
+		{
+			name: "DisableSecureNAT",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.DisableSecureNAT(ctx)
+			},
+			hub:   "subspace",
+			want:  [][]string{{"SecureNatDisable"}},
+			errno: 8, // ERR_HUB_NOT_FOUND
+		},
+		{
+			name: "GetSecureNATStatus",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.GetSecureNATStatus(ctx)
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"SecureNatStatusGet"}},
+			errno: 76, // ERR_OFFLINE
+		},
+		{
+			name: "GetVirtualHost",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.GetVirtualHost(ctx)
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"SecureNatHostGet"}},
+			errno: 8, // ERR_HUB_NOT_FOUND
+		},
+		{
+			name: "SetVirtualHost",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetVirtualHost(ctx, softether.VirtualHost{MAC: "5E-9B-8C-63-E5-20", IP: "10.8.0.1", Mask: "255.255.0.0"})
+			},
+			hub:   "subspace",
+			want:  [][]string{{"SecureNatHostSet", "/MAC:5E-9B-8C-63-E5-20", "/IP:10.8.0.1", "/MASK:255.255.0.0"}},
+			errno: 38, // ERR_INVALID_PARAMETER
+		},
+		{
+			name: "GetNATOptions",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.GetNATOptions(ctx)
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"NatGet"}},
+			errno: 8, // ERR_HUB_NOT_FOUND
+		},
+		{
+			name: "SetNATOptions",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetNATOptions(ctx, softether.NATOptions{
+					Enabled:    true,
+					MTU:        1400,
+					TCPTimeout: 3600 * time.Second,
+					UDPTimeout: 90*time.Second + 500*time.Millisecond,
+					Log:        true,
+				})
+			},
+			hub: "subspace",
+			want: [][]string{
+				{"NatSet", "/MTU:1400", "/TCPTIMEOUT:3600", "/UDPTIMEOUT:90", "/LOG:yes"},
+				{"NatEnable"},
+			},
+			errno: 76, // ERR_OFFLINE
+		},
+		{
+			name: "SetNATOptions disabled",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetNATOptions(ctx, softether.NATOptions{MTU: 1500, TCPTimeout: time.Minute, UDPTimeout: time.Minute})
+			},
+			hub: "subspace",
+			want: [][]string{
+				{"NatSet", "/MTU:1500", "/TCPTIMEOUT:60", "/UDPTIMEOUT:60", "/LOG:no"},
+				{"NatDisable"},
+			},
+			errno: 76, // ERR_OFFLINE
+		},
+		{
+			name: "GetDHCPOptions",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.GetDHCPOptions(ctx)
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"DhcpGet"}},
+			errno: 8, // ERR_HUB_NOT_FOUND
+		},
+		{
+			name: "SetDHCPOptions",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetDHCPOptions(ctx, softether.DHCPOptions{
+					Enabled:   true,
+					Start:     "10.8.0.10",
+					End:       "10.8.0.100",
+					Mask:      "255.255.255.0",
+					LeaseTime: 2 * time.Hour,
+					Gateway:   "10.8.0.1",
+					DNS:       "8.8.8.8",
+					Domain:    "example.com",
+					StaticRoutes: []softether.StaticRoute{
+						{Network: "10.10.0.0", Mask: "255.255.0.0", Gateway: "10.8.0.254"},
+						{Network: "172.16.0.0", Mask: "255.240.0.0", Gateway: "10.8.0.253"},
+					},
+				})
+			},
+			hub: "subspace",
+			want: [][]string{
+				{"DhcpSet", "/START:10.8.0.10", "/END:10.8.0.100", "/MASK:255.255.255.0", "/EXPIRE:7200",
+					"/GW:10.8.0.1", "/DNS:8.8.8.8", "/DNS2:none", "/DOMAIN:example.com", "/LOG:no",
+					"/PUSHROUTE:10.10.0.0/255.255.0.0/10.8.0.254,172.16.0.0/255.240.0.0/10.8.0.253"},
+				{"DhcpEnable"},
+			},
+			errno: 76, // ERR_OFFLINE
+		},
+		{
+			name: "SetDHCPOptions disabled",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetDHCPOptions(ctx, softether.DHCPOptions{Start: "10.8.0.10", End: "10.8.0.100", Mask: "255.255.255.0"})
+			},
+			hub: "subspace",
+			want: [][]string{
+				{"DhcpSet", "/START:10.8.0.10", "/END:10.8.0.100", "/MASK:255.255.255.0", "/EXPIRE:0",
+					"/GW:none", "/DNS:none", "/DNS2:none", "/DOMAIN:", "/LOG:no", "/PUSHROUTE:"},
+				{"DhcpDisable"},
+			},
+			errno: 76, // ERR_OFFLINE
+		},
+		{
+			name: "ListNATTable",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.ListNATTable(ctx)
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"NatTable"}},
+			errno: 76, // ERR_OFFLINE
+		},
+		{
+			name: "ListDHCPLeases",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.ListDHCPLeases(ctx)
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"DhcpTable"}},
+			errno: 76, // ERR_OFFLINE
+		},
+	})
+}
+
+func TestSetOptionsFailure(t *testing.T) {
+	// A failing Set must leave the virtual NAT and DHCP server as they were
+	tests := []struct {
+		name string
+		call func(ctx context.Context, s softether.SoftEther) error
+		set  string
+	}{
+		{
+			name: "SetNATOptions",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetNATOptions(ctx, softether.NATOptions{Enabled: true, MTU: 100})
+			},
+			set: "NatSet",
+		},
+		{
+			name: "SetDHCPOptions",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetDHCPOptions(ctx, softether.DHCPOptions{Enabled: true, Start: "10.8.0.100", End: "10.8.0.10"})
+			},
+			set: "DhcpSet",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, runner := newServer()
+			runner.Fail(test.set, 38) // ERR_INVALID_PARAMETER
+
+			checkError(t, test.call(context.Background(), s), 38)
+			if commands := runner.Commands(); len(commands) != 1 || commands[0].Name != test.set {
+				t.Errorf("commands = %v, want only %s", commands, test.set)
+			}
+		})
+	}
+}
+
+func TestGetSecureNATStatus(t *testing.T) {
+	s, _ := newServer()
+
+	status, err := s.GetSecureNATStatus(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := softether.SecureNATStatus{
+		Hub:          "subspace",
+		TCPSessions:  2,
+		UDPSessions:  1,
+		DHCPClients:  1,
+		RawIPModeNAT: true,
+	}
+	if status != want {
+		t.Errorf("status = %+v, want %+v", status, want)
+	}
+}
+
+func TestGetVirtualHost(t *testing.T) {
+	s, _ := newServer()
+
+	host, err := s.GetVirtualHost(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := softether.VirtualHost{MAC: "5E-9B-8C-63-E5-20", IP: "192.168.30.1", Mask: "255.255.255.0"}
+	if host != want {
+		t.Errorf("host = %+v, want %+v", host, want)
+	}
+}
+
+func TestGetNATOptions(t *testing.T) {
+	s, _ := newServer()
+
+	options, err := s.GetNATOptions(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := softether.NATOptions{
+		Enabled:    true,
+		MTU:        1500,
+		TCPTimeout: 30 * time.Minute,
+		UDPTimeout: time.Minute,
+		Log:        true,
+	}
+	if options != want {
+		t.Errorf("options = %+v, want %+v", options, want)
+	}
+}
+
+func TestGetDHCPOptions(t *testing.T) {
+	s, _ := newServer()
+
+	options, err := s.GetDHCPOptions(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := softether.DHCPOptions{
+		Enabled:   true,
+		Start:     "192.168.30.10",
+		End:       "192.168.30.200",
+		Mask:      "255.255.255.0",
+		LeaseTime: 2 * time.Hour,
+		Gateway:   "192.168.30.1",
+		DNS:       "192.168.30.1",
+		Domain:    "subspace.local",
+		Log:       true,
+		StaticRoutes: []softether.StaticRoute{
+			{Network: "10.10.0.0", Mask: "255.255.0.0", Gateway: "192.168.30.254"},
+			{Network: "172.16.0.0", Mask: "255.240.0.0", Gateway: "192.168.30.253"},
+		},
+	}
+	if !reflect.DeepEqual(options, want) {
+		t.Errorf("options = %+v, want %+v", options, want)
+	}
+}
+
+func TestParseStaticRoutes(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []softether.StaticRoute
+	}{
+		{value: "-"},
+		{value: ""},
+		{
+			value: "192.168.5.0/255.255.255.0/192.168.4.254",
+			want:  []softether.StaticRoute{{Network: "192.168.5.0", Mask: "255.255.255.0", Gateway: "192.168.4.254"}},
+		},
+		{
+			value: "192.168.5.0/255.255.255.0/192.168.4.254,10.0.0.0/255.0.0.0, 10.0.0.0/255.0.0.0/192.168.4.253",
+			want: []softether.StaticRoute{
+				{Network: "192.168.5.0", Mask: "255.255.255.0", Gateway: "192.168.4.254"},
+				{Network: "10.0.0.0", Mask: "255.0.0.0", Gateway: "192.168.4.253"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s, runner := newServer()
+		runner.Handle("DhcpGet", softethertest.Response{
+			Stdout: "Item,Value\nStatic Routing Table to Push,\"" + test.value + "\"\n",
+		})
+
+		options, err := s.GetDHCPOptions(context.Background())
+		if err != nil {
+			t.Fatalf("%q: err = %v", test.value, err)
+		}
+		if !reflect.DeepEqual(options.StaticRoutes, test.want) {
+			t.Errorf("%q: StaticRoutes = %+v, want %+v", test.value, options.StaticRoutes, test.want)
+		}
+	}
+}
+
+func TestListNATTable(t *testing.T) {
+	s, _ := newServer()
+
+	entries, err := s.ListNATTable(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []softether.NATEntry{
+		{
+			ID:                1,
+			Protocol:          "TCP/IP",
+			SrcHost:           "192.168.30.10",
+			SrcPort:           51200,
+			DestHost:          "93.184.216.34",
+			DestPort:          443,
+			CreatedAt:         date("2017-04-20 10:00:00"),
+			LastCommunication: date("2017-04-20 10:11:00"),
+			ReceivedBytes:     678901,
+			SentBytes:         12345,
+			TCPStatus:         "Running",
+		},
+		{
+			ID:                2,
+			Protocol:          "UDP/IP",
+			SrcHost:           "192.168.30.10",
+			SrcPort:           53000,
+			DestHost:          "192.168.30.1",
+			DestPort:          53,
+			CreatedAt:         date("2017-04-20 10:10:00"),
+			LastCommunication: date("2017-04-20 10:10:01"),
+			ReceivedBytes:     128,
+			SentBytes:         64,
+		},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries = %+v, want %+v", entries, want)
+	}
+}
+
+func TestListDHCPLeases(t *testing.T) {
+	s, _ := newServer()
+
+	leases, err := s.ListDHCPLeases(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []softether.DHCPLease{
+		{
+			ID:        1,
+			LeasedAt:  date("2017-04-20 10:00:00"),
+			ExpiresAt: date("2017-04-20 12:00:00"),
+			MAC:       "00-AC-11-22-33-44",
+			IP:        "192.168.30.10",
+			HostName:  "laptop",
+		},
+	}
+	if !reflect.DeepEqual(leases, want) {
+		t.Errorf("leases = %+v, want %+v", leases, want)
+	}
+}
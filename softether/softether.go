@@ -1,356 +1,150 @@
 package softether
 
 import (
-	"bufio"
-	"bytes"
-	"fmt"
-	"os"
-	"os/exec"
+	"context"
 	"regexp"
-	"strconv"
-	"strings"
 	"time"
 )
 
-// SoftEther is a struct which holds the IP, Password, and Hub of the SoftEther server.
+// SoftEther is a struct which holds the IP, Port, Password, and Hub of the SoftEther server.
+// IP may also be a hostname or an IPv6 address; Port defaults to DefaultPort.
+// Commands are executed through Runner, or through a LocalRunner when Runner is nil.
+// Each call is aborted when its context is done, or after Timeout if it is non-zero.
 type SoftEther struct {
-	IP       string
-	Password string
-	Hub      string
+	IP          string
+	Port        int
+	Password    string
+	Hub         string
+	Runner      Runner
+	Timeout     time.Duration
+	Fingerprint string // If set, each call first checks the server certificate against it, see GetServerFingerprint
 }
 
 const SOFT_ETHER_TABLE_HEADER_KEY = "Item"
 
 var reFindIntegers = regexp.MustCompile("[0-9]+")
-var cleanBytesOutput = func(aString string) (int, error) {
-	return strconv.Atoi(strings.Join(reFindIntegers.FindAllString(aString, -1), ""))
-}
 
 // GetServerStatus executes vpncmd and gets the server status info from the SoftEther server.
-func (s SoftEther) GetServerStatus() (status map[string]string, returnCode int) {
+func (s SoftEther) GetServerStatus(ctx context.Context) (status ServerStatus, err error) {
 
 	// Command to execute
-	// vpncmd /server [IP]:992 /password:[PASSWORD] /cmd ServerStatusGet
-	cmd := exec.Command(
-		"vpncmd",
-		"/server",
-		s.IP+":992",
-		"/password:"+s.Password,
-		"/cmd",
-		"ServerStatusGet",
-	)
+	// vpncmd /server [IP]:[PORT] /csv /cmd ServerStatusGet
+	cmd := s.command("ServerStatusGet")
 
-	// Local variables
-	statusMap := make(map[string]string)
-	cmdOutput := &bytes.Buffer{} // Stdout buffer
-
-	// Attach buffer to command output and execute
-	cmd.Stdout = cmdOutput
-	err := cmd.Run()
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
 	if err != nil {
-		returnCode, _ = strconv.Atoi(reFindIntegers.FindAllString(err.Error(), -1)[0])
 		return
 	}
 
-	// Prepare iostream and extract data
-	outputScanner := bufio.NewScanner(bytes.NewReader(cmdOutput.Bytes()))
-	for outputScanner.Scan() {
-		if strings.Contains(outputScanner.Text(), "|") {
-			s := strings.Split(outputScanner.Text(), "|")
-			s[0] = strings.Trim(s[0], " ")
-			statusMap[s[0]] = s[1]
-		}
-	}
-
-	// Perform calculations for outgoing and incoming traffic
-	outgoingUnicastBytes, _ := cleanBytesOutput(statusMap["Outgoing Unicast Total Size"])
-	outgoingBroadcastBytes, _ := cleanBytesOutput(statusMap["Outgoing Broadcast Total Size"])
-	incomingUnicastBytes, _ := cleanBytesOutput(statusMap["Incoming Unicast Total Size"])
-	incomingBroadcastBytes, _ := cleanBytesOutput(statusMap["Incoming Broadcast Total Size"])
-	outgoingTotalBytes := strconv.Itoa(outgoingBroadcastBytes + outgoingUnicastBytes)
-	incomingTotalBytes := strconv.Itoa(incomingBroadcastBytes + incomingUnicastBytes)
-
-	// Put it all together
-	status = map[string]string{
-		"numberOfSessions":  statusMap["Number of Sessions"],
-		"numberOfUsers":     statusMap["Number of Users"],
-		"currentServerTime": statusMap["Current Time"][0:19],
-		"serverStartTime":   statusMap["Server Started at"][0:11] + statusMap["Server Started at"][17:],
-		"incomingBytes":     incomingTotalBytes,
-		"outgoingBytes":     outgoingTotalBytes,
+	// Extract data
+	statusMap, err := parseKeyValue(cmd, cmdOutput)
+	if err != nil {
+		return
 	}
 
+	status = parseServerStatus(statusMap)
 	return
 }
 
 // GetSessionList executes vpncmd and gets the session list from the SoftEther server for a specific Hub.
-func (s SoftEther) GetSessionList() (sessionListMap map[int]map[string]string, returnCode int) {
+func (s SoftEther) GetSessionList(ctx context.Context) (sessionList []Session, err error) {
 
 	// Command to execute
-	// vpncmd /server [IP]:992 /password:[PASSWORD] /hub:[HUB] /cmd SessionList
-	cmd := exec.Command(
-		"vpncmd",
-		"/server",
-		s.IP+":992",
-		"/password:"+s.Password,
-		"/hub:"+s.Hub,
-		"/cmd",
-		"SessionList",
-	)
-
-	// Local variables
-	sessionListMap = make(map[int]map[string]string)
-	cmdOutput := &bytes.Buffer{} // Stdout buffer
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd SessionList
+	cmd := s.hubCommand("SessionList")
 
-	// Attach buffer to command output and execute
-	cmd.Stdout = cmdOutput
-	err := cmd.Run()
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
 	if err != nil {
-		returnCode, _ = strconv.Atoi(reFindIntegers.FindAllString(err.Error(), -1)[0])
 		return
 	}
 
-	// Prepare iostream and extract data
-	outputScanner := bufio.NewScanner(bytes.NewReader(cmdOutput.Bytes()))
-	pos := 0
-	for outputScanner.Scan() {
-		if strings.Contains(outputScanner.Text(), "|") {
-			s := strings.Split(outputScanner.Text(), "|")
-			s[0] = strings.Trim(s[0], " ")
-
-			if SOFT_ETHER_TABLE_HEADER_KEY == s[0] {
-				continue // Skip table header
-			}
-
-			if _, ok := sessionListMap[pos]; !ok {
-				sessionListMap[pos] = make(map[string]string)
-			}
-
-			if s[0] == "Transfer Bytes" || s[0] == "Transfer Packets" {
-				aBytes, _ := cleanBytesOutput(s[1])
-				s[1] = strconv.Itoa(aBytes)
-			}
-
-			sessionListMap[pos][s[0]] = s[1]
-
-			if s[0] == "Transfer Packets" {
-				pos++
-			}
-		}
+	// Extract data
+	rows, err := parseTable(cmd, cmdOutput, "Session Name")
+	for _, row := range rows {
+		sessionList = append(sessionList, parseSession(row))
 	}
 
 	return
 }
 
 // GetSessionInfo executes vpncmd and gets the session information for a specific Session Name
-func (s SoftEther) GetSessionInfo(sessionName string) (sessionInfo map[string]string, returnCode int) {
+func (s SoftEther) GetSessionInfo(ctx context.Context, sessionName string) (session Session, err error) {
 	// Command to execute
-	// vpncmd /server [IP]:992 /password:[PASSWORD] /hub:[HUB] /cmd SessionGet [SESSION_NAME]
-	cmd := exec.Command(
-		"vpncmd",
-		"/server",
-		s.IP+":992",
-		"/password:"+s.Password,
-		"/hub:"+s.Hub,
-		"/cmd",
-		"SessionGet", sessionName,
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd SessionGet [SESSION_NAME]
+	cmd := s.hubCommand(
+		"SessionGet",
+		sessionName,
 	)
 
-	// Local variables
-	sessionInfo = make(map[string]string)
-	cmdOutput := &bytes.Buffer{} // Stdout buffer
-
-	// Attach buffer to command output and execute
-	cmd.Stdout = cmdOutput
-	err := cmd.Run()
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
 	if err != nil {
-		returnCode, _ = strconv.Atoi(reFindIntegers.FindAllString(err.Error(), -1)[0])
 		return
 	}
 
-	// Prepare iostream and extract data
-	outputScanner := bufio.NewScanner(bytes.NewReader(cmdOutput.Bytes()))
-	for outputScanner.Scan() {
-		if strings.Contains(outputScanner.Text(), "|") {
-			s := strings.Split(outputScanner.Text(), "|")
-			s[0] = strings.Trim(s[0], " ")
-
-			if SOFT_ETHER_TABLE_HEADER_KEY == s[0] {
-				continue // Skip table header
-			}
-
-			sessionInfo[s[0]] = s[1]
-		}
-	}
-
-	for key, value := range sessionInfo {
-		switch key {
-		case
-			"Outgoing Data Size",
-			"Incoming Data Size",
-			"Outgoing Unicast Packets",
-			"Outgoing Unicast Total Size",
-			"Outgoing Broadcast Packets",
-			"Outgoing Broadcast Total Size",
-			"Incoming Unicast Packets",
-			"Incoming Unicast Total Size",
-			"Incoming Broadcast Packets",
-			"Incoming Broadcast Total Size":
-			// Convert "4,734,874 bytes" to "4734874"
-			formattedValue, _ := cleanBytesOutput(value)
-			sessionInfo[key] = strconv.Itoa(formattedValue)
-		case
-			"First Session has been Established since",
-			"Current Session has been Established since":
-			// Convert "2017-04-19 (Wed) 02:05:16" to "2017-04-19 02:05:16"
-			sessionInfo[key] = value[0:11] + value[17:]
-		}
+	// Extract data
+	sessionInfo, err := parseKeyValue(cmd, cmdOutput)
+	if err != nil {
+		return
 	}
 
+	session = parseSession(sessionInfo)
 	return
 }
 
 // GetUserList executes vpncmd and gets the user list from the SoftEther server for a specific Hub.
-func (s SoftEther) GetUserList() (userListMap map[int]map[string]string, returnCode int) {
+func (s SoftEther) GetUserList(ctx context.Context) (userList []User, err error) {
 
 	// Command to execute
-	// vpncmd /server [IP]:992 /password:[PASSWORD] /hub:[HUB] /cmd SessionList
-	cmd := exec.Command(
-		"vpncmd",
-		"/server",
-		s.IP+":992",
-		"/password:"+s.Password,
-		"/hub:"+s.Hub,
-		"/cmd",
-		"UserList",
-	)
-
-	// Local variables
-	userListMap = make(map[int]map[string]string)
-	cmdOutput := &bytes.Buffer{} // Stdout buffer
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd UserList
+	cmd := s.hubCommand("UserList")
 
-	// Attach buffer to command output and execute
-	cmd.Stdout = cmdOutput
-	err := cmd.Run()
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
 	if err != nil {
-		returnCode, _ = strconv.Atoi(reFindIntegers.FindAllString(err.Error(), -1)[0])
 		return
 	}
 
-	// Prepare iostream and extract data
-	outputScanner := bufio.NewScanner(bytes.NewReader(cmdOutput.Bytes()))
-	pos := 0
-	for outputScanner.Scan() {
-		if strings.Contains(outputScanner.Text(), "|") {
-			s := strings.Split(outputScanner.Text(), "|")
-			s[0] = strings.Trim(s[0], " ")
-
-			if SOFT_ETHER_TABLE_HEADER_KEY == s[0] {
-				continue // Skip table header
-			}
-
-			if _, ok := userListMap[pos]; !ok {
-				userListMap[pos] = make(map[string]string)
-			}
-
-			userListMap[pos][s[0]] = s[1]
-
-			if s[0] == "Transfer Packets" {
-				pos++
-			}
-		}
-	}
-	for _, user := range userListMap {
-		fmt.Println(user)
-		for key, value := range user {
-			switch key {
-			case
-				"Transfer Bytes",
-				"Transfer Packets":
-				// Convert "4,734,874 bytes" to "4734874"
-				formattedValue, _ := cleanBytesOutput(value)
-				user[key] = strconv.Itoa(formattedValue)
-			case
-				"Last Login":
-				// Convert "2017-04-19 (Wed) 02:05:16" to "2017-04-19 02:05:16"
-				if "(None)" != value {
-					user[key] = value[0:11] + value[17:]
-				}
-			}
-		}
+	// Extract data
+	rows, err := parseTable(cmd, cmdOutput, "User Name")
+	for _, row := range rows {
+		userList = append(userList, parseUser(row))
 	}
+
 	return
 }
 
 // GetUserInfo executes vpncmd and gets the details of a specific User for a specific Hub.
-func (s SoftEther) GetUserInfo(id string) (userInfo map[string]string, returnCode int) {
+func (s SoftEther) GetUserInfo(ctx context.Context, id string) (user User, err error) {
 
 	// Command to execute
-	// vpncmd /server [IP]:992 /password:[PASSWORD] /hub:[HUB] /cmd UserGet [NAME]
-	cmd := exec.Command(
-		"vpncmd",
-		"/server",
-		s.IP+":992",
-		"/password:"+s.Password,
-		"/hub:"+s.Hub,
-		"/cmd",
-		"UserGet", id,
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd UserGet [NAME]
+	cmd := s.hubCommand(
+		"UserGet",
+		id,
 	)
 
-	// Local variables
-	userInfo = make(map[string]string)
-	cmdOutput := &bytes.Buffer{} // Stdout buffer
-
-	// Attach buffer to command output and execute
-	cmd.Stdout = cmdOutput
-	err := cmd.Run()
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
 	if err != nil {
-		returnCode, _ = strconv.Atoi(reFindIntegers.FindAllString(err.Error(), -1)[0])
 		return
 	}
 
-	// Prepare iostream and extract data
-	outputScanner := bufio.NewScanner(bytes.NewReader(cmdOutput.Bytes()))
-	for outputScanner.Scan() {
-		if strings.Contains(outputScanner.Text(), "|") {
-			s := strings.Split(outputScanner.Text(), "|")
-			s[0] = strings.Trim(s[0], " ")
-
-			if SOFT_ETHER_TABLE_HEADER_KEY == s[0] {
-				continue // Skip table header
-			}
-
-			userInfo[s[0]] = s[1]
-		}
-	}
-
-	for key, value := range userInfo {
-		switch key {
-		case
-			"Outgoing Unicast Packets",
-			"Outgoing Unicast Total Size",
-			"Outgoing Broadcast Packets",
-			"Outgoing Broadcast Total Size",
-			"Incoming Unicast Packets",
-			"Incoming Unicast Total Size",
-			"Incoming Broadcast Packets",
-			"Incoming Broadcast Total Size":
-			// Convert "4,734,874 bytes" to "4734874"
-			formattedValue, _ := cleanBytesOutput(value)
-			userInfo[key] = strconv.Itoa(formattedValue)
-		case
-			"Created on",
-			"Updated on":
-			// Convert "2017-04-19 (Wed) 02:05:16" to "2017-04-19 02:05:16"
-			userInfo[key] = value[0:11] + value[17:]
-		}
+	// Extract data
+	userInfo, err := parseKeyValue(cmd, cmdOutput)
+	if err != nil {
+		return
 	}
 
+	user = parseUser(userInfo)
 	return
 }
 
 // CreateUser executes vpncmd and creates a User for a specific Hub.
-func (s SoftEther) CreateUser(args ...interface{}) (returnCode int) {
+// It takes an id and an email, and optionally a description and a group name.
+func (s SoftEther) CreateUser(ctx context.Context, args ...interface{}) (err error) {
 
 	// Mandatory parameters
 	var id string
@@ -358,6 +152,7 @@ func (s SoftEther) CreateUser(args ...interface{}) (returnCode int) {
 
 	// Optional parameters
 	var description string
+	var group string
 
 	// Ensure that we have at least 2 parameters
 	if 2 > len(args) {
@@ -388,149 +183,104 @@ func (s SoftEther) CreateUser(args ...interface{}) (returnCode int) {
 			}
 			description = param
 
+		case 3: // group
+			param, ok := p.(string)
+			if !ok {
+				panic("Fourth parameter (group) not type string.")
+			}
+			group = param
+
 		default:
 			panic("Wrong parameter count.")
 		}
 	}
 
 	// Command to execute
-	// vpncmd /server [IP]:992 /password:[PASSWORD] /hub:[HUB] /cmd UserCreate [NAME] /GROUP:[GROUP] /REALNAME:[EMAIL] /NOTE:[DESCRIPTION]
-	cmd := exec.Command(
-		"vpncmd",
-		"/server",
-		s.IP+":992",
-		"/password:"+s.Password,
-		"/hub:"+s.Hub,
-		"/cmd",
-		"UserCreate", id,
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd UserCreate [NAME] /GROUP:[GROUP] /REALNAME:[EMAIL] /NOTE:[DESCRIPTION]
+	cmd := s.hubCommand(
+		"UserCreate",
+		id,
 		"/REALNAME:"+email,
 		"/NOTE:"+description,
-		"/GROUP:",
+		"/GROUP:"+group,
 	)
-	cmdOutput := &bytes.Buffer{} // Stdout buffer
-
-	// Attach buffer to command output and execute
-	cmd.Stdout = cmdOutput
-	err := cmd.Run() // will wait for command to return
-	if err != nil {
-		returnCode, _ = strconv.Atoi(reFindIntegers.FindAllString(err.Error(), -1)[0])
-		return
-	}
 
+	// Execute
+	_, err = s.execute(ctx, cmd)
 	return
 }
 
 // SetUserPassword executes vpncmd and updates a specific User's password in a specific Hub.
-func (s SoftEther) SetUserPassword(id string, password string) (returnCode int) {
+func (s SoftEther) SetUserPassword(ctx context.Context, id string, password string) (err error) {
 	// Command to execute
-	// vpncmd /server [IP]:992 /password:[PASSWORD] /hub:[HUB] /cmd UserPasswordSet [NAME] /GROUP:[GROUP] /REALNAME:[ALIAS] /NOTE:[EMAIL]
-	cmd := exec.Command(
-		"vpncmd",
-		"/server",
-		s.IP+":992",
-		"/password:"+s.Password,
-		"/hub:"+s.Hub,
-		"/cmd",
-		"UserPasswordSet", id,
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /in:[SCRIPT], SCRIPT: UserPasswordSet [NAME] /PASSWORD:[PASSWORD]
+	cmd := s.hubCommand(
+		"UserPasswordSet",
+		id,
 		"/PASSWORD:"+password,
 	)
-	cmdOutput := &bytes.Buffer{} // Stdout buffer
-
-	// Attach buffer to command output and execute
-	cmd.Stdout = cmdOutput
-	err := cmd.Run() // will wait for command to return
-	if err != nil {
-		returnCode, _ = strconv.Atoi(reFindIntegers.FindAllString(err.Error(), -1)[0])
-		return
-	}
+	cmd.Secrets = []int{1}
 
+	// Execute
+	_, err = s.execute(ctx, cmd)
 	return
 }
 
-// SetUserAlias executes vpncmd and updates a specific User's information in a specific Hub.
-func (s SoftEther) SetUserInfo(id, email, description string) (returnCode int) {
+// SetUserInfo executes vpncmd and updates a specific User's information in a specific Hub.
+// The group of the User is kept; use GroupJoin and GroupUnjoin to change it.
+func (s SoftEther) SetUserInfo(ctx context.Context, id, email, description string) (err error) {
+
+	// UserSet replaces the group too, so pass the current one along
+	user, err := s.GetUserInfo(ctx, id)
+	if err != nil {
+		return
+	}
 
 	// Command to execute
-	// vpncmd /server [IP]:992 /password:[PASSWORD] /hub:[HUB] /cmd UserSet [NAME] /GROUP:[GROUP] /REALNAME:[EMAIL] /NOTE:[DESCRIPTION]
-	cmd := exec.Command(
-		"vpncmd",
-		"/server",
-		s.IP+":992",
-		"/password:"+s.Password,
-		"/hub:"+s.Hub,
-		"/cmd",
-		"UserSet", id,
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd UserSet [NAME] /GROUP:[GROUP] /REALNAME:[EMAIL] /NOTE:[DESCRIPTION]
+	cmd := s.hubCommand(
+		"UserSet",
+		id,
 		"/REALNAME:"+email,
 		"/NOTE:"+description,
-		"/GROUP:",
+		"/GROUP:"+user.GroupName,
 	)
-	cmdOutput := &bytes.Buffer{} // Stdout buffer
-
-	// Attach buffer to command output and execute
-	cmd.Stdout = cmdOutput
-	err := cmd.Run() // will wait for command to return
-	if err != nil {
-		returnCode, _ = strconv.Atoi(reFindIntegers.FindAllString(err.Error(), -1)[0])
-		return
-	}
 
+	// Execute
+	_, err = s.execute(ctx, cmd)
 	return
 }
 
 // DeleteUser executes vpncmd and deletes a specific User in a specific Hub.
-func (s SoftEther) DeleteUser(id string) (returnCode int) {
+func (s SoftEther) DeleteUser(ctx context.Context, id string) (err error) {
 	// Command to execute
-	// vpncmd /server [IP]:992 /password:[PASSWORD] /hub:[HUB] /cmd UserDelete [NAME]
-	cmd := exec.Command(
-		"vpncmd",
-		"/server",
-		s.IP+":992",
-		"/password:"+s.Password,
-		"/hub:"+s.Hub,
-		"/cmd",
-		"UserDelete", id,
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd UserDelete [NAME]
+	cmd := s.hubCommand(
+		"UserDelete",
+		id,
 	)
-	cmdOutput := &bytes.Buffer{} // Stdout buffer
-
-	// Attach buffer to command output and execute
-	cmd.Stdout = cmdOutput
-	err := cmd.Run() // will wait for command to return
-	if err != nil {
-		returnCode, _ = strconv.Atoi(reFindIntegers.FindAllString(err.Error(), -1)[0])
-		return
-	}
 
+	// Execute
+	_, err = s.execute(ctx, cmd)
 	return
 }
 
 // DisconnectSession executes vpncmd and disconnects a specific session
-func (s SoftEther) DisconnectSession(sessionName string) (returnCode int) {
+func (s SoftEther) DisconnectSession(ctx context.Context, sessionName string) (err error) {
 	// Command to execute
-	// vpncmd /server [IP]:992 /password:[PASSWORD] /hub:[HUB] /cmd SessionDisconnect [SESSION_NAME]
-	cmd := exec.Command(
-		"vpncmd",
-		"/server",
-		s.IP+":992",
-		"/password:"+s.Password,
-		"/hub:"+s.Hub,
-		"/cmd",
-		"SessionDisconnect", sessionName,
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd SessionDisconnect [SESSION_NAME]
+	cmd := s.hubCommand(
+		"SessionDisconnect",
+		sessionName,
 	)
-	cmdOutput := &bytes.Buffer{} // Stdout buffer
-
-	// Attach buffer to command output and execute
-	cmd.Stdout = cmdOutput
-	err := cmd.Run() // will wait for command to return
-	if err != nil {
-		returnCode, _ = strconv.Atoi(reFindIntegers.FindAllString(err.Error(), -1)[0])
-		return
-	}
 
+	// Execute
+	_, err = s.execute(ctx, cmd)
 	return
 }
 
 // SetUserEnabled executes vpncmd to enable/disable a specified Username
-func (s SoftEther) SetUserEnabled(username string, enabled bool) (returnCode int) {
+func (s SoftEther) SetUserEnabled(ctx context.Context, username string, enabled bool) (err error) {
 	var (
 		expirationDate string
 	)
@@ -544,73 +294,76 @@ func (s SoftEther) SetUserEnabled(username string, enabled bool) (returnCode int
 	}
 
 	// Command to execute
-	// vpncmd /server [IP]:992 /password:[PASSWORD] /hub:[HUB] /cmd UserExpiresSet [SESSION_NAME] /EXPIRES:[EXPIRATION_DATE}]
-	cmd := exec.Command(
-		"vpncmd",
-		"/server",
-		s.IP+":992",
-		"/password:"+s.Password,
-		"/hub:"+s.Hub,
-		"/cmd",
-		"UserExpiresSet", username,
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd UserExpiresSet [SESSION_NAME] /EXPIRES:[EXPIRATION_DATE}]
+	cmd := s.hubCommand(
+		"UserExpiresSet",
+		username,
 		"/expires:"+expirationDate,
 	)
-	cmdOutput := &bytes.Buffer{} // Stdout buffer
-
-	// Attach buffer to command output and execute
-	cmd.Stdout = cmdOutput
-	err := cmd.Run() // will wait for command to return
-	if err != nil {
-		returnCode, _ = strconv.Atoi(reFindIntegers.FindAllString(err.Error(), -1)[0])
-		return
-	}
 
+	// Execute
+	_, err = s.execute(ctx, cmd)
 	return
 }
 
-// SetPreSharedKey executes vpncmd to modify the preshared key
-func (s SoftEther) SetPreSharedKey(preSharedKey string) (returnCode int) {
-	// Command to execute
-	// vpncmd /server [IP]:992 /password:[PASSWORD] /cmd IPsecEnable [/L2TP:yes|no] [/L2TPRAW:yes|no] [/ETHERIP:yes|no] [/PSK:pre-shared-key] [/DEFAULTHUB:default_hub]
-	cmd := exec.Command(
-		"vpncmd",
-		"/server",
-		s.IP+":992",
-		"/password:"+s.Password,
-		"/cmd",
-		"IPsecEnable",
-		"/L2TP:yes",
-		"/L2TPRAW:no",
-		"/ETHERIP:no",
-		"/DEFAULTHUB:"+s.Hub,
-		"/PSK:"+preSharedKey,
-	)
-	cmdOutput := &bytes.Buffer{} // Stdout buffer
-	printCommand(cmd)
+// SetPreSharedKey executes vpncmd to modify the preshared key, keeping the rest of the IPsec configuration
+func (s SoftEther) SetPreSharedKey(ctx context.Context, preSharedKey string) (err error) {
+	return s.SetIPsecConfig(ctx, func(config *IPsecConfig) {
+		config.PreSharedKey = preSharedKey
+	})
+}
 
-	// Attach buffer to command output and execute
-	cmd.Stdout = cmdOutput
-	err := cmd.Run() // will wait for command to return
-	if err != nil {
-		returnCode, _ = strconv.Atoi(reFindIntegers.FindAllString(err.Error(), -1)[0])
-		return
+// command builds a server-wide vpncmd command.
+func (s SoftEther) command(name string, args ...string) Command {
+	server, _ := s.Address() // validated by execute
+	return Command{
+		Server:   server,
+		Password: s.Password,
+		Name:     name,
+		Args:     args,
 	}
+}
 
-	return
+// hubCommand builds a vpncmd command which operates on the Hub of s.
+func (s SoftEther) hubCommand(name string, args ...string) Command {
+	return s.hubCommandFor(s.Hub, name, args...)
 }
 
-func printCommand(cmd *exec.Cmd) {
-	fmt.Printf("==> Executing: %s\n", strings.Join(cmd.Args, " "))
+// hubCommandFor builds a vpncmd command which operates on the Hub named hub.
+func (s SoftEther) hubCommandFor(hub string, name string, args ...string) Command {
+	cmd := s.command(name, args...)
+	cmd.Hub = hub
+	return cmd
 }
 
-func printError(err error) {
-	if err != nil {
-		os.Stderr.WriteString(fmt.Sprintf("==> Error: %s\n", err.Error()))
+// execute runs cmd through the configured Runner and returns its output. Failures,
+// including a non-zero exit code of vpncmd, are returned as an *Error.
+func (s SoftEther) execute(ctx context.Context, cmd Command) (output []byte, err error) {
+	if _, err = s.Address(); err != nil {
+		return nil, NewKindError(KindInvalidAddress, cmd.Name, err)
+	}
+	if err = cmd.checkLine(); err != nil {
+		return nil, err
+	}
+
+	runner := s.Runner
+	if runner == nil {
+		runner = LocalRunner{}
+	}
+
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
 	}
-}
 
-func printOutput(outs []byte) {
-	if len(outs) > 0 {
-		fmt.Printf("==> Output: %s\n", string(outs))
+	if s.Fingerprint != "" {
+		if err = s.verifyFingerprint(ctx, cmd.Name); err != nil {
+			return nil, err
+		}
 	}
+
+	output, stderr, exitCode, err := runner.Run(ctx, cmd)
+	err = checkResult(ctx, cmd, output, stderr, exitCode, err)
+	return
 }
@@ -0,0 +1,734 @@
+package softether_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether/softethertest"
+)
+
+// newServer returns a SoftEther for the Hub "subspace" which runs its commands
+// through a fake vpncmd replaying softethertest.Fixtures.
+func newServer() (softether.SoftEther, *softethertest.Runner) {
+	runner := softethertest.NewRunner()
+	s := softether.SoftEther{IP: "10.0.0.1", Password: "subspace", Hub: "subspace", Runner: runner}
+	return s, runner
+}
+
+// date returns the time vpncmd prints as "2017-04-19 (Wed) 02:05:16" in UTC.
+func date(value string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04:05.999", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// checkError fails t unless err is the *softether.Error for the SoftEther error code errno.
+func checkError(t *testing.T, err error, errno int) {
+	t.Helper()
+
+	var cmdError *softether.Error
+	if !errors.As(err, &cmdError) {
+		t.Fatalf("err = %v, want *softether.Error with code %d", err, errno)
+	}
+	if !errors.Is(err, softether.NewError(errno)) {
+		t.Errorf("errors.Is(%v, NewError(%d)) = false", err, errno)
+	}
+	if cmdError.Kind != softether.KindErrorCode || cmdError.Code != errno {
+		t.Errorf("err = %s %d, want %s %d", cmdError.Kind, cmdError.Code, softether.KindErrorCode, errno)
+	}
+	if cmdError.Name != softether.Strerror(errno) {
+		t.Errorf("err.Name = %q, want Strerror(%d) = %q", cmdError.Name, errno, softether.Strerror(errno))
+	}
+}
+
+// checkKind fails t unless err is a *softether.Error of kind.
+func checkKind(t *testing.T, err error, kind softether.Kind) {
+	t.Helper()
+
+	var cmdError *softether.Error
+	if !errors.As(err, &cmdError) || cmdError.Kind != kind {
+		t.Fatalf("err = %v, want *softether.Error of kind %s", err, kind)
+	}
+}
+
+// checkCommands fails t unless runner ran exactly the vpncmd commands want, given
+// as the command name followed by its arguments, on the Hub hub.
+func checkCommands(t *testing.T, runner *softethertest.Runner, hub string, want ...[]string) {
+	t.Helper()
+
+	commands := runner.Commands()
+	if len(commands) != len(want) {
+		t.Fatalf("ran %d commands %v, want %d", len(commands), commands, len(want))
+	}
+	for i, cmd := range commands {
+		got := append([]string{cmd.Name}, cmd.Args...)
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("command %d = %q, want %q", i, got, want[i])
+		}
+		if cmd.Hub != hub {
+			t.Errorf("command %d %s runs on Hub %q, want %q", i, cmd.Name, cmd.Hub, hub)
+		}
+		if cmd.Server != "10.0.0.1:992" || cmd.Password != "subspace" {
+			t.Errorf("command %d %s connects to %s with %q", i, cmd.Name, cmd.Server, cmd.Password)
+		}
+	}
+}
+
+// secretValues returns the values of the arguments of cmd which its Secrets mark.
+func secretValues(cmd softether.Command) []string {
+	var values []string
+	for _, i := range cmd.Secrets {
+		arg := cmd.Args[i]
+		if j := strings.Index(arg, ":"); strings.HasPrefix(arg, "/") && j >= 0 {
+			arg = arg[j+1:]
+		}
+		values = append(values, arg)
+	}
+	return values
+}
+
+// checkRedacted fails t if a secret shows up in the logged form of the commands of runner.
+func checkRedacted(t *testing.T, runner *softethertest.Runner, secrets ...string) {
+	t.Helper()
+
+	for _, cmd := range runner.Commands() {
+		line := cmd.String()
+		for _, secret := range secrets {
+			if strings.Contains(line, secret) {
+				t.Errorf("%s exposes %q", line, secret)
+			}
+		}
+	}
+}
+
+// newKeyPair returns a self-signed ECDSA certificate for commonName.
+func newKeyPair(t *testing.T, commonName string) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// loadedFile is a file vpncmd was told to load through a parameter such as /LOADCERT:.
+type loadedFile struct {
+	Path string
+	Data []byte
+	Mode os.FileMode
+}
+
+// loadRunner is a softethertest.Runner which also reads the files its commands load,
+// before the SoftEther method removes them.
+type loadRunner struct {
+	*softethertest.Runner
+	files map[string]loadedFile // By parameter, e.g. "/LOADCERT:", or "" for the argument of CAAdd
+}
+
+func newLoadRunner() *loadRunner {
+	return &loadRunner{Runner: softethertest.NewRunner(), files: make(map[string]loadedFile)}
+}
+
+func (r *loadRunner) Run(ctx context.Context, cmd softether.Command) (stdout, stderr []byte, exitCode int, err error) {
+	load := func(param, path string) {
+		file := loadedFile{Path: path}
+		if info, err := os.Stat(path); err == nil {
+			file.Mode = info.Mode().Perm()
+		}
+		file.Data, _ = os.ReadFile(path)
+		r.files[param] = file
+	}
+
+	if cmd.Name == "CAAdd" && len(cmd.Args) == 1 {
+		load("", cmd.Args[0])
+	}
+	for _, arg := range cmd.Args {
+		for _, param := range []string{"/LOADCERT:", "/LOADKEY:"} {
+			if strings.HasPrefix(arg, param) {
+				load(param, strings.TrimPrefix(arg, param))
+			}
+		}
+	}
+	return r.Runner.Run(ctx, cmd)
+}
+
+// checkKeyPair fails t unless runner loaded the leaf certificate and private key of cert
+// from PEM files only the current user can read, which are gone by now.
+func checkKeyPair(t *testing.T, runner *loadRunner, cert tls.Certificate) {
+	t.Helper()
+
+	certFile, keyFile := runner.files["/LOADCERT:"], runner.files["/LOADKEY:"]
+	loaded, err := tls.X509KeyPair(certFile.Data, keyFile.Data)
+	if err != nil {
+		t.Fatalf("loaded key pair: %v", err)
+	}
+	if !bytes.Equal(loaded.Certificate[0], cert.Certificate[0]) {
+		t.Errorf("loaded certificate differs from the one passed")
+	}
+	for _, file := range []loadedFile{certFile, keyFile} {
+		if file.Mode != 0600 {
+			t.Errorf("%s mode = %v, want 0600", file.Path, file.Mode)
+		}
+		if _, err := os.Stat(file.Path); !os.IsNotExist(err) {
+			t.Errorf("%s is left behind: %v", file.Path, err)
+		}
+	}
+}
+
+// commandTest is a call of a SoftEther method and the vpncmd commands it runs.
+type commandTest struct {
+	name    string
+	call    func(ctx context.Context, s softether.SoftEther) error
+	hub     string     // Hub the commands run on
+	want    [][]string // vpncmd commands, as the command name followed by its arguments
+	secrets []string   // Secrets of the last command, which must be redacted
+	errno   int        // error code the last command fails with
+}
+
+// runCommandTests checks that each test runs its commands, and that it returns the
+// error code its last command fails with.
+func runCommandTests(t *testing.T, tests []commandTest) {
+	t.Helper()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			s, runner := newServer()
+			if err := test.call(ctx, s); err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			checkCommands(t, runner, test.hub, test.want...)
+
+			commands := runner.Commands()
+			if last := commands[len(commands)-1]; !reflect.DeepEqual(secretValues(last), test.secrets) {
+				t.Errorf("%s Secrets = %v, want the arguments holding %q", last.Name, last.Secrets, test.secrets)
+			}
+			checkRedacted(t, runner, test.secrets...)
+
+			s, runner = newServer()
+			runner.Fail(test.want[len(test.want)-1][0], test.errno)
+			checkError(t, test.call(ctx, s), test.errno)
+		})
+	}
+}
+
+func TestMethods(t *testing.T) {
+	runCommandTests(t, []commandTest{
+		{
+			name: "GetSessionList",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.GetSessionList(ctx)
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"SessionList"}},
+			errno: 8, // ERR_HUB_NOT_FOUND
+		},
+		{
+			name: "GetSessionInfo",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.GetSessionInfo(ctx, "SID-1-[L2TP]-2")
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"SessionGet", "SID-1-[L2TP]-2"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "DisconnectSession",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.DisconnectSession(ctx, "SID-1-[L2TP]-2")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"SessionDisconnect", "SID-1-[L2TP]-2"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "GetUserList",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.GetUserList(ctx)
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"UserList"}},
+			errno: 12, // ERR_ACCESS_DENIED
+		},
+		{
+			name: "GetUserInfo",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.GetUserInfo(ctx, "1")
+				return err
+			},
+			hub:   "subspace",
+			want:  [][]string{{"UserGet", "1"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "CreateUser",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.CreateUser(ctx, "3", "new@ecoworkinc.com")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"UserCreate", "3", "/REALNAME:new@ecoworkinc.com", "/NOTE:", "/GROUP:"}},
+			errno: 66, // ERR_USER_ALREADY_EXISTS
+		},
+		{
+			name: "CreateUser with description and group",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.CreateUser(ctx, "3", "new@ecoworkinc.com", "Note", "staff")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"UserCreate", "3", "/REALNAME:new@ecoworkinc.com", "/NOTE:Note", "/GROUP:staff"}},
+			errno: 65, // ERR_GROUP_NOT_FOUND
+		},
+		{
+			name: "SetUserPassword",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetUserPassword(ctx, "1", "s3cret")
+			},
+			hub:     "subspace",
+			want:    [][]string{{"UserPasswordSet", "1", "/PASSWORD:s3cret"}},
+			secrets: []string{"s3cret"},
+			errno:   29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "DeleteUser",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.DeleteUser(ctx, "1")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"UserDelete", "1"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "SetUserEnabled",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetUserEnabled(ctx, "1", true)
+			},
+			hub:   "subspace",
+			want:  [][]string{{"UserExpiresSet", "1", "/expires:none"}},
+			errno: 52, // ERR_NOT_ENOUGH_RIGHT
+		},
+	})
+}
+
+func TestGetServerStatus(t *testing.T) {
+	s, runner := newServer()
+
+	status, err := s.GetServerStatus(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	checkCommands(t, runner, "", []string{"ServerStatusGet"})
+
+	want := softether.ServerStatus{
+		ServerType:        "Standalone Server",
+		NumberOfSockets:   14,
+		NumberOfHubs:      1,
+		NumberOfSessions:  2,
+		NumberOfMACTables: 3,
+		NumberOfIPTables:  4,
+		NumberOfUsers:     2,
+		NumberOfGroups:    0,
+		ServerStartTime:   date("2017-04-19 02:05:16"),
+		CurrentServerTime: date("2017-04-20 10:11:12.345"),
+		Traffic: softether.Traffic{
+			OutgoingUnicastPackets:   12340,
+			OutgoingUnicastBytes:     4734874,
+			OutgoingBroadcastPackets: 120,
+			OutgoingBroadcastBytes:   10240,
+			IncomingUnicastPackets:   23450,
+			IncomingUnicastBytes:     1234567,
+			IncomingBroadcastPackets: 230,
+			IncomingBroadcastBytes:   20480,
+		},
+	}
+	if !reflect.DeepEqual(status, want) {
+		t.Errorf("status = %+v, want %+v", status, want)
+	}
+	if status.IncomingBytes() != 1255047 || status.OutgoingBytes() != 4745114 {
+		t.Errorf("IncomingBytes, OutgoingBytes = %d, %d", status.IncomingBytes(), status.OutgoingBytes())
+	}
+}
+
+func TestGetSessionList(t *testing.T) {
+	s, runner := newServer()
+
+	sessionList, err := s.GetSessionList(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	checkCommands(t, runner, "subspace", []string{"SessionList"})
+
+	want := []softether.Session{
+		{
+			Name:           "SID-SECURENAT-1",
+			Location:       "Local Session",
+			Username:       "SecureNAT",
+			ClientHostName: "Virtual Host",
+		},
+		{
+			Name:            "SID-1-[L2TP]-2",
+			Location:        "Local Session",
+			Username:        "1",
+			ClientHostName:  "203.0.113.10",
+			TransferBytes:   4734874,
+			TransferPackets: 12345,
+		},
+	}
+	if !reflect.DeepEqual(sessionList, want) {
+		t.Errorf("sessionList = %+v, want %+v", sessionList, want)
+	}
+}
+
+func TestGetSessionInfo(t *testing.T) {
+	s, _ := newServer()
+
+	session, err := s.GetSessionInfo(context.Background(), "SID-1-[L2TP]-2")
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	tests := []struct {
+		field     string
+		got, want interface{}
+	}{
+		{"Name", session.Name, "SID-1-[L2TP]-2"},
+		{"Username", session.Username, "1"},
+		{"ClientIP", session.ClientIP, "203.0.113.10"},
+		{"ClientHostName", session.ClientHostName, "203.0.113.10"},
+		{"ClientPort", session.ClientPort, 4500},
+		{"ClientProduct", session.ClientProduct, "L2TP VPN Client"},
+		{"ClientVersion", session.ClientVersion, "1.00"},
+		{"ClientOS", session.ClientOS, "L2TP VPN Client"},
+		{"ServerHostName", session.ServerHostName, "127.0.0.1"},
+		{"ServerIP", session.ServerIP, "127.0.0.1"},
+		{"ServerPort", session.ServerPort, 1701},
+		{"Encryption", session.Encryption, "Enabled (Algorithm: AES128-SHA)"},
+		{"Encrypted", session.Encrypted, true},
+		{"Compressed", session.Compressed, false},
+		{"TCPConnections", session.TCPConnections, 1},
+		{"MaxTCPConnections", session.MaxTCPConnections, 1},
+		{"OutgoingDataSize", session.OutgoingDataSize, int64(4734874)},
+		{"IncomingDataSize", session.IncomingDataSize, int64(1234567)},
+		{"ConnectionStarted", session.ConnectionStarted, date("2017-04-19 02:05:16")},
+		{"OutgoingUnicastPackets", session.OutgoingUnicastPackets, int64(12340)},
+		{"IncomingBroadcastBytes", session.IncomingBroadcastBytes, int64(20480)},
+	}
+	for _, test := range tests {
+		if !reflect.DeepEqual(test.got, test.want) {
+			t.Errorf("%s = %v, want %v", test.field, test.got, test.want)
+		}
+	}
+}
+
+func TestGetUserList(t *testing.T) {
+	s, runner := newServer()
+
+	userList, err := s.GetUserList(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	checkCommands(t, runner, "subspace", []string{"UserList"})
+
+	want := []softether.User{
+		{
+			Name:            "1",
+			FullName:        "test@ecoworkinc.com",
+			Description:     "New Account",
+			AuthType:        softether.AuthPassword,
+			NumberOfLogins:  3,
+			LastLogin:       date("2017-04-19 02:05:16"),
+			TransferBytes:   4734874,
+			TransferPackets: 12345,
+		},
+		{
+			Name:           "2",
+			FullName:       "other@ecoworkinc.com",
+			Description:    "Other Account, with a | pipe",
+			AuthType:       softether.AuthPassword,
+			ExpirationDate: date("2017-04-18 10:00:00"),
+		},
+	}
+	if !reflect.DeepEqual(userList, want) {
+		t.Errorf("userList = %+v, want %+v", userList, want)
+	}
+}
+
+func TestGetUserInfo(t *testing.T) {
+	s, _ := newServer()
+
+	user, err := s.GetUserInfo(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := softether.User{
+		Name:           "1",
+		FullName:       "test@ecoworkinc.com",
+		Description:    "New Account",
+		AuthType:       softether.AuthPassword,
+		NumberOfLogins: 3,
+		CreatedOn:      date("2017-04-19 01:00:00"),
+		UpdatedOn:      date("2017-04-19 01:30:00"),
+		Traffic: softether.Traffic{
+			OutgoingUnicastPackets:   12340,
+			OutgoingUnicastBytes:     4724634,
+			OutgoingBroadcastPackets: 5,
+			OutgoingBroadcastBytes:   10240,
+			IncomingUnicastPackets:   2345,
+			IncomingUnicastBytes:     1214087,
+			IncomingBroadcastPackets: 6,
+			IncomingBroadcastBytes:   20480,
+		},
+	}
+	if !reflect.DeepEqual(user, want) {
+		t.Errorf("user = %+v, want %+v", user, want)
+	}
+}
+
+func TestSetUserInfo(t *testing.T) {
+	ctx := context.Background()
+	s, runner := newServer()
+	runner.Handle("UserGet", softethertest.Response{
+		Stdout: "Item,Value\nUser Name,1\nGroup Name,staff\n",
+	})
+
+	if err := s.SetUserInfo(ctx, "1", "new@ecoworkinc.com", "Renamed"); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	checkCommands(t, runner, "subspace",
+		[]string{"UserGet", "1"},
+		[]string{"UserSet", "1", "/REALNAME:new@ecoworkinc.com", "/NOTE:Renamed", "/GROUP:staff"},
+	)
+
+	// A missing user fails before UserSet
+	s, runner = newServer()
+	runner.Fail("UserGet", 29)
+	checkError(t, s.SetUserInfo(ctx, "1", "new@ecoworkinc.com", "Renamed"), 29)
+	checkCommands(t, runner, "subspace", []string{"UserGet", "1"})
+}
+
+func TestSetUserEnabled(t *testing.T) {
+	s, runner := newServer()
+
+	if err := s.SetUserEnabled(context.Background(), "1", false); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	cmd := runner.Commands()[0]
+	expires := strings.TrimPrefix(cmd.Args[1], "/expires:")
+	expiresAt, err := time.ParseInLocation("2006/01/02 15:04:05", expires, time.Local)
+	if err != nil {
+		t.Fatalf("%s: %v", cmd, err)
+	}
+	if !expiresAt.Before(time.Now()) {
+		t.Errorf("disabled user expires at %v, after now", expiresAt)
+	}
+}
+
+func TestSetUserPasswordRedacted(t *testing.T) {
+	s, runner := newServer()
+
+	if err := s.SetUserPassword(context.Background(), "1", "s3cret"); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	cmd := runner.Commands()[0]
+	if !reflect.DeepEqual(cmd.Secrets, []int{1}) {
+		t.Errorf("Secrets = %v, want the password", cmd.Secrets)
+	}
+	checkRedacted(t, runner, "s3cret", "subspace\n")
+
+	// Only the password is redacted, even where it shows up in other arguments
+	s, runner = newServer()
+	if err := s.SetUserPassword(context.Background(), "bob", "b"); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if line := runner.Commands()[0].String(); !strings.HasSuffix(line, " UserPasswordSet bob /PASSWORD:********") {
+		t.Errorf("String() = %s", line)
+	}
+}
+
+func TestSetPreSharedKey(t *testing.T) {
+	s, runner := newServer()
+
+	if err := s.SetPreSharedKey(context.Background(), "abcdefg"); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	checkCommands(t, runner, "",
+		[]string{"IPsecGet"},
+		[]string{"IPsecEnable", "/L2TP:yes", "/L2TPRAW:no", "/ETHERIP:yes", "/PSK:abcdefg", "/DEFAULTHUB:subspace"},
+	)
+	checkRedacted(t, runner, "abcdefg")
+
+	s, runner = newServer()
+	runner.Fail("IPsecEnable", 52)
+	checkError(t, s.SetPreSharedKey(context.Background(), "abcdefg"), 52)
+}
+
+func TestErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("error codes", func(t *testing.T) {
+		sentinels := map[int]error{
+			1:   softether.ErrConnectFailed,
+			8:   softether.ErrHubNotFound,
+			9:   softether.ErrAuthFailed,
+			12:  softether.ErrAccessDenied,
+			23:  softether.ErrInternalError,
+			29:  softether.ErrObjectNotFound,
+			33:  softether.ErrNotSupported,
+			38:  softether.ErrInvalidParameter,
+			52:  softether.ErrNotEnoughRight,
+			53:  softether.ErrListenerNotFound,
+			54:  softether.ErrListenerAlreadyExists,
+			57:  softether.ErrHubAlreadyExists,
+			59:  softether.ErrLinkAlreadyExists,
+			61:  softether.ErrLinkIsOffline,
+			65:  softether.ErrGroupNotFound,
+			66:  softether.ErrUserAlreadyExists,
+			67:  softether.ErrGroupAlreadyExists,
+			112: softether.ErrObjectExists,
+			117: softether.ErrBadCommandOrParam,
+		}
+		for errno, sentinel := range sentinels {
+			s, runner := newServer()
+			runner.Fail("ServerStatusGet", errno)
+
+			_, err := s.GetServerStatus(ctx)
+			checkError(t, err, errno)
+			if !errors.Is(err, sentinel) {
+				t.Errorf("errors.Is(%v, %v) = false", err, sentinel)
+			}
+			if errors.Is(err, softether.ErrParse) {
+				t.Errorf("errors.Is(%v, ErrParse) = true", err)
+			}
+		}
+	})
+
+	t.Run("message printed by vpncmd", func(t *testing.T) {
+		s, runner := newServer()
+		runner.Handle("HubList", softethertest.Response{
+			Stdout:   "Error occurred. (Error code: 8)\nThe specified Virtual Hub does not exist on the server.\n",
+			Stderr:   "warning",
+			ExitCode: 8,
+		})
+
+		_, err := s.ListHubs(ctx)
+		checkError(t, err, 8)
+
+		cmdError := err.(*softether.Error)
+		if cmdError.Message != "The specified Virtual Hub does not exist on the server." {
+			t.Errorf("Message = %q", cmdError.Message)
+		}
+		if cmdError.Command != "HubList" || cmdError.Stderr != "warning" {
+			t.Errorf("Command, Stderr = %q, %q", cmdError.Command, cmdError.Stderr)
+		}
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		s, runner := newServer()
+		runner.Handle("SessionList", softethertest.ErrorResponse(117))
+
+		_, err := s.GetSessionList(ctx)
+		checkError(t, err, 117)
+	})
+
+	t.Run("unexpected output", func(t *testing.T) {
+		s, runner := newServer()
+		runner.Handle("ServerStatusGet", softethertest.Response{Stdout: "\n"})
+
+		_, err := s.GetServerStatus(ctx)
+		checkKind(t, err, softether.KindParse)
+		if !errors.Is(err, softether.ErrParse) {
+			t.Errorf("errors.Is(%v, ErrParse) = false", err)
+		}
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		s, runner := newServer()
+		s.IP = "not a host"
+
+		_, err := s.GetServerStatus(ctx)
+		checkKind(t, err, softether.KindInvalidAddress)
+		if len(runner.Commands()) != 0 {
+			t.Errorf("ran %v", runner.Commands())
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		s, _ := newServer()
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := s.GetServerStatus(canceled)
+		checkKind(t, err, softether.KindCanceled)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("errors.Is(%v, context.Canceled) = false", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		s, _ := newServer()
+		expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+		defer cancel()
+
+		_, err := s.GetServerStatus(expired)
+		checkKind(t, err, softether.KindTimeout)
+		if !errors.Is(err, softether.ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("err = %v, want ErrTimeout wrapping context.DeadlineExceeded", err)
+		}
+	})
+}
+
+func TestStrerror(t *testing.T) {
+	tests := []struct {
+		errno int
+		want  string
+	}{
+		{0, "ERR_NO_ERROR"},
+		{8, "ERR_HUB_NOT_FOUND"},
+		{29, "ERR_OBJECT_NOT_FOUND"},
+		{148, "ERR_VPN_CONNECTION_DISCONNECTED_DUE_TO_SYSTEM_SUSPENSION"},
+		{70, ""},
+		{-1, ""},
+	}
+	for _, test := range tests {
+		if got := softether.Strerror(test.errno); got != test.want {
+			t.Errorf("Strerror(%d) = %q, want %q", test.errno, got, test.want)
+		}
+	}
+
+	if err := softether.NewError(70); err.Name != "ERR_70" {
+		t.Errorf("NewError(70).Name = %q, want ERR_70", err.Name)
+	}
+}
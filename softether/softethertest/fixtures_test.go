@@ -0,0 +1,308 @@
+package softethertest_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/csv"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether/softethertest"
+)
+
+// labelIDs lists, for each fixture, the IDs of the vpncmd string table entries its labels
+// are printed from: row labels of "Item,Value" tables, column headers and captions.
+var labelIDs = map[string][]string{
+	"AccessList": {
+		"SM_ACCESS_COLUMN_0", "SM_ACCESS_COLUMN_1", "SM_ACCESS_COLUMN_2", "SM_ACCESS_COLUMN_3",
+		"SM_ACCESS_COLUMN_4", "SM_ACCESS_COLUMN_5",
+	},
+	"CAList": {
+		"CMD_CAList_COLUMN_ID", "CM_CERT_COLUMN_1", "CM_CERT_COLUMN_2", "CM_CERT_COLUMN_3",
+	},
+	"CascadeList": {
+		"SM_LINK_COLUMN_1", "SM_LINK_COLUMN_2", "SM_LINK_COLUMN_3", "SM_LINK_COLUMN_4",
+		"SM_LINK_COLUMN_5",
+	},
+	"CascadeStatusGet": {
+		"CM_ST_ACCOUNT_NAME", "CM_ST_CONNECTED", "CM_ST_VLAN_ID", "CM_ST_SERVER_NAME",
+		"CM_ST_SERVER_PORT", "CM_ST_SERVER_P_NAME", "CM_ST_SERVER_P_VER", "CM_ST_SERVER_P_BUILD",
+		"CM_ST_START_TIME", "CM_ST_FIRST_ESTAB_TIME", "CM_ST_CURR_ESTAB_TIME", "CM_ST_NUM_ESTABLISHED",
+		"CM_ST_HALF_CONNECTION", "CM_ST_UDP_ACCEL_USING", "CM_ST_USE_ENCRYPT", "CM_ST_USE_COMPRESS",
+		"CM_ST_NUM_TCP", "CM_ST_SEND_UCAST_NUM", "CM_ST_SEND_UCAST_SIZE", "CM_ST_SEND_BCAST_NUM",
+		"CM_ST_SEND_BCAST_SIZE", "CM_ST_RECV_UCAST_NUM", "CM_ST_RECV_UCAST_SIZE", "CM_ST_RECV_BCAST_NUM",
+		"CM_ST_RECV_BCAST_SIZE",
+	},
+	"DhcpGet": {
+		"CMD_DhcpGet_Column_USE", "CMD_DhcpGet_Column_IP1", "CMD_DhcpGet_Column_IP2",
+		"CMD_DhcpGet_Column_MASK", "CMD_DhcpGet_Column_LEASE", "CMD_DhcpGet_Column_GW",
+		"CMD_DhcpGet_Column_DNS", "CMD_DhcpGet_Column_DNS2", "CMD_DhcpGet_Column_DOMAIN",
+		"CMD_DhcpGet_Column_Log", "CMD_DhcpGet_Column_PUSHROUTE",
+	},
+	"DhcpTable": {
+		"DHCP_DHCP_ID", "DHCP_LEASED_TIME", "DHCP_EXPIRE_TIME", "DHCP_MAC_ADDRESS", "DHCP_IP_ADDRESS",
+		"DHCP_HOSTNAME",
+	},
+	"EtherIpClientList": {
+		"SM_ETHERIP_COLUMN_0", "SM_ETHERIP_COLUMN_1", "SM_ETHERIP_COLUMN_2",
+	},
+	"GroupGet": {
+		"CMD_GroupGet_Column_NAME", "CMD_GroupGet_Column_REALNAME", "CMD_GroupGet_Column_NOTE",
+		"CMD_GroupGet_Column_POLICY", "CMD_PolicyList_Column_1", "CMD_PolicyList_Column_2",
+		"CMD_PolicyList_Column_3", "CMD_GroupGet_Column_MEMBERS",
+	},
+	"GroupList": {
+		"SM_GROUPLIST_NAME", "SM_GROUPLIST_REALNAME", "SM_GROUPLIST_NOTE", "SM_GROUPLIST_NUMUSERS",
+	},
+	"HubList": {
+		"SM_HUB_COLUMN_1", "SM_HUB_COLUMN_2", "SM_HUB_COLUMN_3", "SM_HUB_COLUMN_4", "SM_HUB_COLUMN_5",
+		"SM_HUB_COLUMN_6", "SM_HUB_COLUMN_7", "SM_HUB_COLUMN_8", "SM_HUB_COLUMN_9", "SM_HUB_COLUMN_10",
+		"SM_HUB_COLUMN_11", "SM_SESS_COLUMN_6", "SM_SESS_COLUMN_7",
+	},
+	"IPsecGet": {
+		"CMD_IPsecGet_PRINT_L2TP", "CMD_IPsecGet_PRINT_L2TPRAW", "CMD_IPsecGet_PRINT_ETHERIP",
+		"CMD_IPsecGet_PRINT_PSK", "CMD_IPsecGet_PRINT_DEFAULTHUB",
+	},
+	"ListenerList": {
+		"CM_LISTENER_COLUMN_1", "CM_LISTENER_COLUMN_2",
+	},
+	"NatGet": {
+		"CMD_NatGet_Column_USE", "CMD_NetGet_Column_MTU", "CMD_NatGet_Column_TCP",
+		"CMD_NatGet_Column_UDP", "CMD_SecureNatHostGet_Column_LOG",
+	},
+	"NatTable": {
+		"NM_NAT_ID", "NM_NAT_PROTOCOL", "NM_NAT_SRC_HOST", "NM_NAT_SRC_PORT", "NM_NAT_DST_HOST",
+		"NM_NAT_DST_PORT", "NM_NAT_CREATED", "NM_NAT_LAST_COMM", "NM_NAT_SIZE", "NM_NAT_TCP_STATUS",
+	},
+	"OpenVpnGet": {
+		"CMD_OpenVpnGet_PRINT_Enabled", "CMD_OpenVpnGet_PRINT_Ports",
+	},
+	"RadiusServerGet": {
+		"CMD_RadiusServerGet_STATUS", "CMD_RadiusServerGet_HOST", "CMD_RadiusServerGet_PORT",
+		"CMD_RadiusServerGet_RetryInterval",
+	},
+	"SecureNatHostGet": {
+		"CMD_SecureNatHostGet_Column_MAC", "CMD_SecureNatHostGet_Column_IP",
+		"CMD_SecureNatHostGet_Column_MASK",
+	},
+	"SecureNatStatusGet": {
+		"SM_HUB_COLUMN_1", "NM_STATUS_TCP", "NM_STATUS_UDP", "NM_STATUS_ICMP", "NM_STATUS_DNS",
+		"NM_STATUS_DHCP", "SM_SNAT_IS_KERNEL", "SM_SNAT_IS_RAW",
+	},
+	"ServerStatusGet": {
+		"SM_ST_SERVER_TYPE", "SM_ST_NUM_TCP", "SM_ST_NUM_HUB_TOTAL", "SM_ST_NUM_SESSION_TOTAL",
+		"SM_ST_NUM_MAC_TABLE", "SM_ST_NUM_IP_TABLE", "SM_ST_NUM_USERS", "SM_ST_NUM_GROUPS",
+		"SM_ST_CLIENT_LICENSE", "SM_ST_BRIDGE_LICENSE", "SM_ST_SEND_UCAST_NUM", "SM_ST_SEND_UCAST_SIZE",
+		"SM_ST_SEND_BCAST_NUM", "SM_ST_SEND_BCAST_SIZE", "SM_ST_RECV_UCAST_NUM", "SM_ST_RECV_UCAST_SIZE",
+		"SM_ST_RECV_BCAST_NUM", "SM_ST_RECV_BCAST_SIZE", "SM_ST_START_TIME", "SM_ST_CURRENT_TIME",
+		"SM_ST_CURRENT_TICK",
+	},
+	"SessionGet": {
+		"CM_ST_SESSION_NAME", "CM_ST_VLAN_ID", "SM_CLIENT_IP", "SM_CLIENT_HOSTNAME",
+		"SM_SESS_STATUS_USERNAME", "SM_SESS_STATUS_REALUSER", "CM_ST_SERVER_P_NAME",
+		"CM_ST_SERVER_P_VER", "CM_ST_SERVER_P_BUILD", "CM_ST_START_TIME", "CM_ST_FIRST_ESTAB_TIME",
+		"CM_ST_CURR_ESTAB_TIME", "CM_ST_HALF_CONNECTION", "CM_ST_QOS", "CM_ST_NUM_TCP", "CM_ST_MAX_TCP",
+		"CM_ST_USE_ENCRYPT", "CM_ST_USE_COMPRESS", "CM_ST_UNDERLAY_PROTOCOL", "CM_ST_UDP_ACCEL_ENABLED",
+		"CM_ST_UDP_ACCEL_USING", "CM_ST_BRIDGE_MODE", "CM_ST_MONITOR_MODE", "CM_ST_SEND_SIZE",
+		"CM_ST_RECV_SIZE", "CM_ST_SEND_UCAST_NUM", "CM_ST_SEND_UCAST_SIZE", "CM_ST_SEND_BCAST_NUM",
+		"CM_ST_SEND_BCAST_SIZE", "CM_ST_RECV_UCAST_NUM", "CM_ST_RECV_UCAST_SIZE", "CM_ST_RECV_BCAST_NUM",
+		"CM_ST_RECV_BCAST_SIZE", "SM_NODE_CLIENT_NAME", "SM_NODE_CLIENT_VER", "SM_NODE_CLIENT_BUILD",
+		"SM_NODE_CLIENT_OS_NAME", "SM_NODE_CLIENT_OS_VER", "SM_NODE_CLIENT_OS_PID",
+		"SM_NODE_CLIENT_HOST", "SM_NODE_CLIENT_IP", "SM_NODE_CLIENT_PORT", "SM_NODE_SERVER_HOST",
+		"SM_NODE_SERVER_IP", "SM_NODE_SERVER_PORT", "SM_NODE_PROXY_HOSTNAME", "SM_NODE_PROXY_IP",
+		"SM_NODE_PROXY_PORT",
+	},
+	"SessionList": {
+		"SM_SESS_COLUMN_1", "SM_SESS_COLUMN_8", "SM_SESS_COLUMN_2", "SM_SESS_COLUMN_3",
+		"SM_SESS_COLUMN_4", "SM_SESS_COLUMN_5", "SM_SESS_COLUMN_6", "SM_SESS_COLUMN_7",
+	},
+	"SstpGet": {
+		"CMD_SstpEnable_PRINT_Enabled",
+	},
+	"StatusGet": {
+		"SM_HUB_STATUS_HUBNAME", "SM_HUB_STATUS_ONLINE", "SM_HUB_COLUMN_3", "SM_HUB_SECURE_NAT",
+		"SM_HUB_NUM_SESSIONS", "SM_HUB_NUM_SESSIONS_CLIENT", "SM_HUB_NUM_SESSIONS_BRIDGE",
+		"SM_HUB_NUM_ACCESSES", "SM_HUB_NUM_USERS", "SM_HUB_NUM_GROUPS", "SM_HUB_NUM_MAC_TABLES",
+		"SM_HUB_NUM_IP_TABLES", "SM_HUB_NUM_LOGIN", "SM_HUB_COLUMN_10", "SM_HUB_COLUMN_11",
+		"SM_HUB_CREATED_TIME", "SM_ST_SEND_UCAST_NUM", "SM_ST_SEND_UCAST_SIZE", "SM_ST_SEND_BCAST_NUM",
+		"SM_ST_SEND_BCAST_SIZE", "SM_ST_RECV_UCAST_NUM", "SM_ST_RECV_UCAST_SIZE", "SM_ST_RECV_BCAST_NUM",
+		"SM_ST_RECV_BCAST_SIZE",
+	},
+	"UserGet": {
+		"CMD_UserGet_Column_Name", "CMD_UserGet_Column_RealName", "CMD_UserGet_Column_Note",
+		"CMD_UserGet_Column_Group", "CMD_UserGet_Column_Expires", "CMD_UserGet_Column_AuthType",
+		"SM_USERINFO_NUMLOGIN", "SM_USERINFO_CREATE", "SM_USERINFO_UPDATE", "SM_ST_SEND_UCAST_NUM",
+		"SM_ST_SEND_UCAST_SIZE", "SM_ST_SEND_BCAST_NUM", "SM_ST_SEND_BCAST_SIZE", "SM_ST_RECV_UCAST_NUM",
+		"SM_ST_RECV_UCAST_SIZE", "SM_ST_RECV_BCAST_NUM", "SM_ST_RECV_BCAST_SIZE",
+	},
+	"UserList": {
+		"SM_USER_COLUMN_1", "SM_USER_COLUMN_2", "SM_USER_COLUMN_3", "SM_USER_COLUMN_4",
+		"SM_USER_COLUMN_5", "SM_USER_COLUMN_6", "SM_USER_COLUMN_7", "SM_USERINFO_EXPIRE",
+		"SM_SESS_COLUMN_6", "SM_SESS_COLUMN_7",
+	},
+}
+
+// stringTable reads the English string table of vpncmd, keyed by string ID, out of the
+// hamcore.se2 archive in vpncmd_mac.zip at the root of the repository.
+func stringTable(t *testing.T) map[string]string {
+	t.Helper()
+
+	archive, err := zip.OpenReader("../../vpncmd_mac.zip")
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skip("vpncmd_mac.zip is not available")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	var hamcore []byte
+	for _, f := range archive.File {
+		if f.Name != "hamcore.se2" {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		hamcore, err = io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data := hamcoreFile(t, hamcore, "strtable_en.stb")
+	table := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.IndexAny(line, " \t"); i > 0 {
+			table[line[:i]] = strings.TrimSpace(line[i:])
+		}
+	}
+	return table
+}
+
+// hamcoreFile extracts the file name out of the HamCore archive hamcore: the magic
+// "HamCore" and the number of files, followed by the name length (including a NUL
+// terminator, which is not stored), name, size, compressed size and offset of each
+// file, all big endian. The files are zlib compressed.
+func hamcoreFile(t *testing.T, hamcore []byte, name string) []byte {
+	t.Helper()
+
+	r := bytes.NewReader(hamcore)
+	magic := make([]byte, 7)
+	var count uint32
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != "HamCore" {
+		t.Fatalf("hamcore.se2 is not a HamCore archive: %q, %v", magic, err)
+	}
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := uint32(0); i < count; i++ {
+		var nameLength uint32
+		if err := binary.Read(r, binary.BigEndian, &nameLength); err != nil || nameLength == 0 {
+			t.Fatalf("invalid HamCore entry %d: %v", i, err)
+		}
+		fileName := make([]byte, nameLength-1)
+		var entry struct{ Size, CompressedSize, Offset uint32 }
+		if _, err := io.ReadFull(r, fileName); err != nil {
+			t.Fatal(err)
+		}
+		if err := binary.Read(r, binary.BigEndian, &entry); err != nil {
+			t.Fatal(err)
+		}
+		if string(fileName) != name {
+			continue
+		}
+
+		end := uint64(entry.Offset) + uint64(entry.CompressedSize)
+		if end > uint64(len(hamcore)) {
+			t.Fatalf("%s is out of bounds", name)
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(hamcore[entry.Offset:end]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(zr)
+		if err != nil || len(data) != int(entry.Size) {
+			t.Fatalf("%s: %d bytes, want %d: %v", name, len(data), entry.Size, err)
+		}
+		return data
+	}
+
+	t.Fatalf("no %s in hamcore.se2", name)
+	return nil
+}
+
+// fixtureLabels returns the fields of stdout, and its labels: the first column of an
+// "Item,Value" table, or else the column headers.
+func fixtureLabels(t *testing.T, stdout string) (fields, labels map[string]bool) {
+	t.Helper()
+
+	reader := csv.NewReader(strings.NewReader(stdout))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields, labels = make(map[string]bool), make(map[string]bool)
+	for _, record := range records {
+		for _, field := range record {
+			fields[strings.TrimSpace(field)] = true
+		}
+	}
+	if strings.Join(records[0], ",") != "Item,Value" {
+		for _, column := range records[0] {
+			labels[strings.TrimSpace(column)] = true
+		}
+		return
+	}
+	for _, record := range records[1:] {
+		if len(record) == 2 {
+			labels[strings.TrimSpace(record[0])] = true
+		}
+	}
+	return
+}
+
+func TestFixtureLabels(t *testing.T) {
+	table := stringTable(t)
+
+	for name, response := range softethertest.Fixtures {
+		if response.Stdout == "" {
+			continue
+		}
+		ids, ok := labelIDs[name]
+		if !ok {
+			t.Errorf("%s: no string IDs listed", name)
+			continue
+		}
+
+		fields, labels := fixtureLabels(t, response.Stdout)
+		for _, id := range ids {
+			value, ok := table[id]
+			if !ok {
+				t.Errorf("%s: no string %s", name, id)
+				continue
+			}
+			if !fields[value] {
+				t.Errorf("%s: no %q (%s)", name, value, id)
+			}
+			delete(labels, value)
+		}
+		for label := range labels {
+			t.Errorf("%s: label %q is not one of its strings", name, label)
+		}
+	}
+}
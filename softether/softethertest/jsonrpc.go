@@ -0,0 +1,125 @@
+package softethertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+)
+
+// JSONRPCCall is a call received by a JSONRPCHandler.
+type JSONRPCCall struct {
+	Method   string
+	Params   map[string]interface{}
+	Password string // Value of the X-VPNADMIN-PASSWORD header
+}
+
+// JSONRPCHandler is an http.Handler standing in for the JSON-RPC API of a SoftEther
+// server, for use with httptest.NewTLSServer. It replays canned results keyed by
+// method name. Methods without a canned result which change the configuration
+// (Create*, Set*, Delete*, ...) echo their parameters, like SoftEther does; other
+// methods fail with ERR_NOT_SUPPORTED.
+type JSONRPCHandler struct {
+	mu      sync.Mutex
+	results map[string]string
+	errors  map[string]int
+	calls   []JSONRPCCall
+}
+
+// NewJSONRPCHandler returns a JSONRPCHandler replaying the synthetic JSONRPCFixtures.
+func NewJSONRPCHandler() *JSONRPCHandler {
+	h := &JSONRPCHandler{
+		results: make(map[string]string),
+		errors:  make(map[string]int),
+	}
+	for method, result := range JSONRPCFixtures {
+		h.results[method] = result
+	}
+	return h
+}
+
+// Handle sets the JSON result replayed for method.
+func (h *JSONRPCHandler) Handle(method string, result string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.results[method] = result
+	delete(h.errors, method)
+}
+
+// Fail makes method fail with errno.
+func (h *JSONRPCHandler) Fail(method string, errno int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.errors[method] = errno
+}
+
+// Calls returns the calls received so far, oldest first.
+func (h *JSONRPCHandler) Calls() []JSONRPCCall {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]JSONRPCCall(nil), h.calls...)
+}
+
+// ServeHTTP answers a JSON-RPC request to /api/.
+func (h *JSONRPCHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/api/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	var request struct {
+		ID     string                 `json:"id"`
+		Method string                 `json:"method"`
+		Params map[string]interface{} `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.mu.Lock()
+	h.calls = append(h.calls, JSONRPCCall{
+		Method:   request.Method,
+		Params:   request.Params,
+		Password: r.Header.Get("X-VPNADMIN-PASSWORD"),
+	})
+	result, ok := h.results[request.Method]
+	errno, failed := h.errors[request.Method]
+	h.mu.Unlock()
+
+	response := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      request.ID,
+	}
+	switch {
+	case failed:
+		response["error"] = map[string]interface{}{
+			"code":    errno,
+			"message": softether.NewError(errno).Message,
+		}
+	case ok:
+		response["result"] = json.RawMessage(result)
+	case isChange(request.Method):
+		response["result"] = request.Params
+	default:
+		response["error"] = map[string]interface{}{
+			"code":    33, // ERR_NOT_SUPPORTED
+			"message": softether.NewError(33).Message,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// isChange reports whether method changes the configuration of the server.
+func isChange(method string) bool {
+	for _, prefix := range []string{"Create", "Set", "Delete", "Add", "Enable", "Disable"} {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
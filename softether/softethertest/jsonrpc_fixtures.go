@@ -0,0 +1,220 @@
+package softethertest
+
+// JSONRPCFixtures maps JSON-RPC methods to synthetic results, written by hand after
+// the API of SoftEther VPN Server 4.29 Build 9680 with the Virtual Hub "subspace".
+// They describe the same server as the vpncmd Fixtures.
+var JSONRPCFixtures = map[string]string{
+	"GetServerStatus": `{
+		"ServerType_u32": 0,
+		"NumTcpConnections_u32": 14,
+		"NumTcpConnectionsLocal_u32": 14,
+		"NumTcpConnectionsRemote_u32": 0,
+		"NumHubTotal_u32": 1,
+		"NumHubStandalone_u32": 1,
+		"NumHubStatic_u32": 0,
+		"NumHubDynamic_u32": 0,
+		"NumSessionsTotal_u32": 2,
+		"NumSessionsLocal_u32": 2,
+		"NumSessionsRemote_u32": 0,
+		"NumMacTables_u32": 3,
+		"NumIpTables_u32": 4,
+		"NumUsers_u32": 2,
+		"NumGroups_u32": 0,
+		"AssignedClientLicenses_u32": 0,
+		"AssignedBridgeLicenses_u32": 0,
+		"Recv.BroadcastBytes_u64": 20480,
+		"Recv.BroadcastCount_u64": 230,
+		"Recv.UnicastBytes_u64": 1234567,
+		"Recv.UnicastCount_u64": 23450,
+		"Send.BroadcastBytes_u64": 10240,
+		"Send.BroadcastCount_u64": 120,
+		"Send.UnicastBytes_u64": 4734874,
+		"Send.UnicastCount_u64": 12340,
+		"CurrentTime_dt": "2017-04-20T10:11:12.345Z",
+		"CurrentTick_u64": 115935642,
+		"StartTime_dt": "2017-04-19T02:05:16.000Z",
+		"TotalMemory_u64": 0,
+		"UsedMemory_u64": 0,
+		"FreeMemory_u64": 0,
+		"TotalPhys_u64": 0,
+		"UsedPhys_u64": 0,
+		"FreePhys_u64": 0
+	}`,
+
+	"EnumSession": `{
+		"HubName_str": "subspace",
+		"SessionList": [
+			{
+				"Name_str": "SID-SECURENAT-1",
+				"RemoteSession_bool": false,
+				"RemoteHostname_str": "",
+				"Username_str": "SecureNAT",
+				"ClientIP_ip": "0.0.0.0",
+				"Hostname_str": "Virtual Host",
+				"MaxNumTcp_u32": 0,
+				"CurrentNumTcp_u32": 0,
+				"PacketSize_u64": 0,
+				"PacketNum_u64": 0,
+				"LinkMode_bool": false,
+				"SecureNATMode_bool": true,
+				"BridgeMode_bool": false,
+				"Layer3Mode_bool": false,
+				"Client_BridgeMode_bool": false,
+				"Client_MonitorMode_bool": false,
+				"VLanId_u32": 0,
+				"CreatedTime_dt": "2017-04-19T02:05:16.000Z",
+				"LastCommTime_dt": "2017-04-20T10:11:12.000Z"
+			},
+			{
+				"Name_str": "SID-1-[L2TP]-2",
+				"RemoteSession_bool": false,
+				"RemoteHostname_str": "",
+				"Username_str": "1",
+				"ClientIP_ip": "203.0.113.10",
+				"Hostname_str": "203.0.113.10",
+				"MaxNumTcp_u32": 1,
+				"CurrentNumTcp_u32": 0,
+				"PacketSize_u64": 4734874,
+				"PacketNum_u64": 12345,
+				"LinkMode_bool": false,
+				"SecureNATMode_bool": false,
+				"BridgeMode_bool": false,
+				"Layer3Mode_bool": false,
+				"Client_BridgeMode_bool": false,
+				"Client_MonitorMode_bool": false,
+				"VLanId_u32": 0,
+				"CreatedTime_dt": "2017-04-19T02:05:16.000Z",
+				"LastCommTime_dt": "2017-04-20T10:11:12.000Z"
+			}
+		]
+	}`,
+
+	"GetSessionStatus": `{
+		"HubName_str": "subspace",
+		"Name_str": "SID-1-[L2TP]-2",
+		"Username_str": "1",
+		"RealUsername_str": "1",
+		"GroupName_str": "",
+		"LinkMode_bool": false,
+		"Client_Ip_Address_ip": "203.0.113.10",
+		"SessionStatus_ClientHostName_str": "203.0.113.10",
+		"Active_bool": true,
+		"Connected_bool": true,
+		"SessionStatus_u32": 4,
+		"ServerName_str": "127.0.0.1",
+		"ServerPort_u32": 1701,
+		"ServerProductName_str": "SoftEther VPN Server (64 bit)",
+		"ServerProductVer_u32": 429,
+		"ServerProductBuild_u32": 9680,
+		"StartTime_dt": "2017-04-19T02:05:16.000Z",
+		"FirstConnectionEstablisiedTime_dt": "2017-04-19T02:05:16.000Z",
+		"CurrentConnectionEstablishTime_dt": "2017-04-19T02:05:16.000Z",
+		"NumConnectionsEatablished_u32": 1,
+		"HalfConnection_bool": false,
+		"QoS_bool": true,
+		"MaxTcpConnections_u32": 1,
+		"NumTcpConnections_u32": 1,
+		"NumTcpConnectionsUpload_u32": 0,
+		"NumTcpConnectionsDownload_u32": 0,
+		"UseEncrypt_bool": true,
+		"CipherName_str": "AES128-SHA",
+		"UseCompress_bool": false,
+		"IsRUDPSession_bool": false,
+		"UnderlayProtocol_str": "Standard TCP/IP (IPv4)",
+		"IsUdpAccelerationEnabled_bool": false,
+		"IsUsingUdpAcceleration_bool": false,
+		"IsBridgeMode_bool": false,
+		"IsMonitorMode_bool": false,
+		"TotalSendSize_u64": 4734874,
+		"TotalRecvSize_u64": 1234567,
+		"Recv.BroadcastBytes_u64": 20480,
+		"Recv.BroadcastCount_u64": 6,
+		"Recv.UnicastBytes_u64": 1214087,
+		"Recv.UnicastCount_u64": 2345,
+		"Send.BroadcastBytes_u64": 10240,
+		"Send.BroadcastCount_u64": 5,
+		"Send.UnicastBytes_u64": 4724634,
+		"Send.UnicastCount_u64": 12340,
+		"ClientProductName_str": "L2TP VPN Client",
+		"ClientProductVer_u32": 100,
+		"ClientProductBuild_u32": 0,
+		"ClientOsName_str": "L2TP VPN Client",
+		"ClientOsVer_str": "",
+		"ClientOsProductId_str": "",
+		"ClientHostname_str": "203.0.113.10",
+		"ClientPort_u32": 4500,
+		"ServerHostname_str": "127.0.0.1",
+		"ServerIpAddress_ip": "127.0.0.1"
+	}`,
+
+	"EnumUser": `{
+		"HubName_str": "subspace",
+		"UserList": [
+			{
+				"Name_str": "1",
+				"GroupName_str": "",
+				"Realname_utf": "test@ecoworkinc.com",
+				"Note_utf": "New Account",
+				"AuthType_u32": 1,
+				"NumLogin_u32": 3,
+				"LastLoginTime_dt": "2017-04-19T02:05:16.000Z",
+				"DenyAccess_bool": false,
+				"IsTrafficInfo_bool": true,
+				"IsExpiresFilled_bool": false,
+				"Expires_dt": "1970-01-01T00:00:00.000Z",
+				"Ex.Recv.BroadcastBytes_u64": 20480,
+				"Ex.Recv.BroadcastCount_u64": 6,
+				"Ex.Recv.UnicastBytes_u64": 1214087,
+				"Ex.Recv.UnicastCount_u64": 2345,
+				"Ex.Send.BroadcastBytes_u64": 10240,
+				"Ex.Send.BroadcastCount_u64": 5,
+				"Ex.Send.UnicastBytes_u64": 4724634,
+				"Ex.Send.UnicastCount_u64": 12340
+			},
+			{
+				"Name_str": "2",
+				"GroupName_str": "",
+				"Realname_utf": "other@ecoworkinc.com",
+				"Note_utf": "Other Account",
+				"AuthType_u32": 1,
+				"NumLogin_u32": 0,
+				"LastLoginTime_dt": "1970-01-01T00:00:00.000Z",
+				"DenyAccess_bool": false,
+				"IsTrafficInfo_bool": true,
+				"IsExpiresFilled_bool": true,
+				"Expires_dt": "2017-04-18T10:00:00.000Z"
+			}
+		]
+	}`,
+
+	"GetUser": `{
+		"HubName_str": "subspace",
+		"Name_str": "1",
+		"GroupName_str": "",
+		"Realname_utf": "test@ecoworkinc.com",
+		"Note_utf": "New Account",
+		"CreatedTime_dt": "2017-04-19T01:00:00.000Z",
+		"UpdatedTime_dt": "2017-04-19T01:30:00.000Z",
+		"ExpireTime_dt": "1970-01-01T00:00:00.000Z",
+		"AuthType_u32": 1,
+		"Auth_Password_str": "",
+		"NumLogin_u32": 3,
+		"Recv.BroadcastBytes_u64": 20480,
+		"Recv.BroadcastCount_u64": 6,
+		"Recv.UnicastBytes_u64": 1214087,
+		"Recv.UnicastCount_u64": 2345,
+		"Send.BroadcastBytes_u64": 10240,
+		"Send.BroadcastCount_u64": 5,
+		"Send.UnicastBytes_u64": 4724634,
+		"Send.UnicastCount_u64": 12340,
+		"UsePolicy_bool": false
+	}`,
+
+	"GetIPsecServices": `{
+		"L2TP_Raw_bool": false,
+		"L2TP_IPsec_bool": true,
+		"EtherIP_IPsec_bool": false,
+		"IPsec_Secret_str": "vpn",
+		"L2TP_DefaultHub_str": "subspace"
+	}`,
+}
@@ -0,0 +1,109 @@
+// Package softethertest provides a fake vpncmd for testing code which uses the
+// softether package without a SoftEther VPN Server.
+package softethertest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+)
+
+// Response is the canned result of a vpncmd command.
+type Response struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	File     string // Written to the file the command saves to, see savePath
+}
+
+// ErrorResponse returns the Response vpncmd produces when a command fails with errno.
+func ErrorResponse(errno int) Response {
+	return Response{
+		Stdout:   fmt.Sprintf("Error occurred. (Error code: %d)\n%s.\n", errno, softether.NewError(errno).Message),
+		ExitCode: errno,
+	}
+}
+
+// Runner is a softether.Runner which replays canned Responses keyed by vpncmd
+// command name. Commands without a Response fail with ERR_BAD_COMMAND_OR_PARAM.
+type Runner struct {
+	mu        sync.Mutex
+	responses map[string]Response
+	commands  []softether.Command
+}
+
+// NewRunner returns a Runner replaying the synthetic Fixtures.
+func NewRunner() *Runner {
+	r := &Runner{responses: make(map[string]Response)}
+	for name, response := range Fixtures {
+		r.responses[name] = response
+	}
+	return r
+}
+
+// Handle sets the Response replayed for the vpncmd command name.
+func (r *Runner) Handle(name string, response Response) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.responses[name] = response
+}
+
+// Fail makes the vpncmd command name fail with errno.
+func (r *Runner) Fail(name string, errno int) {
+	r.Handle(name, ErrorResponse(errno))
+}
+
+// Commands returns the commands run so far, oldest first.
+func (r *Runner) Commands() []softether.Command {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]softether.Command(nil), r.commands...)
+}
+
+// Run records cmd and replays the Response registered for its name. It fails
+// with the error of ctx if ctx is already done.
+func (r *Runner) Run(ctx context.Context, cmd softether.Command) (stdout, stderr []byte, exitCode int, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, -1, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands = append(r.commands, cmd)
+
+	response, ok := r.responses[cmd.Name]
+	if !ok {
+		response = ErrorResponse(117) // ERR_BAD_COMMAND_OR_PARAM
+	}
+
+	// Save certificates, keys and ZIP files like vpncmd does
+	if path := savePath(cmd); response.File != "" && path != "" {
+		if err := os.WriteFile(path, []byte(response.File), 0600); err != nil {
+			return nil, nil, -1, err
+		}
+	}
+
+	return []byte(response.Stdout), []byte(response.Stderr), response.ExitCode, nil
+}
+
+// savePath returns the file cmd saves its result to: the first argument of ServerCertGet,
+// ServerKeyGet and OpenVpnMakeConfig, or the /SAVECERT: parameter of CAGet and UserCertGet.
+func savePath(cmd softether.Command) string {
+	switch cmd.Name {
+	case "ServerCertGet", "ServerKeyGet", "OpenVpnMakeConfig":
+		if len(cmd.Args) > 0 {
+			return cmd.Args[0]
+		}
+	case "CAGet", "UserCertGet":
+		for _, arg := range cmd.Args {
+			if strings.HasPrefix(arg, "/SAVECERT:") {
+				return strings.TrimPrefix(arg, "/SAVECERT:")
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package softether
+
+import "context"
+
+// sstpEnabled is the label of the MS-SSTP setting in the output table of SstpGet.
+const sstpEnabled = "SSTP VPN Clone Server Enabled"
+
+// GetSSTPEnabled executes vpncmd and gets whether the Microsoft SSTP VPN clone server
+// function of the SoftEther server is enabled.
+func (s SoftEther) GetSSTPEnabled(ctx context.Context) (enabled bool, err error) {
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /cmd SstpGet
+	cmd := s.command("SstpGet")
+
+	// Execute
+	cmdOutput, err := s.execute(ctx, cmd)
+	if err != nil {
+		return
+	}
+
+	// Extract data
+	configMap, err := parseKeyValue(cmd, cmdOutput)
+	if err != nil {
+		return
+	}
+
+	value, ok := configMap[sstpEnabled]
+	if !ok {
+		return false, parseFailure(cmd, "no %q in output", sstpEnabled)
+	}
+	return parseBool(value), nil
+}
+
+// SetSSTPEnabled executes vpncmd and enables or disables the Microsoft SSTP VPN clone
+// server function of the SoftEther server. SSTP clients connect to the HTTPS listener ports
+// and must trust the server certificate, see SetServerCert.
+func (s SoftEther) SetSSTPEnabled(ctx context.Context, enabled bool) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /csv /cmd SstpEnable [yes|no]
+	cmd := s.command(
+		"SstpEnable",
+		formatBool(enabled),
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
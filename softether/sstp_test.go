@@ -0,0 +1,77 @@
+package softether_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether/softethertest"
+)
+
+func TestSSTPCommands(t *testing.T) {
+	runCommandTests(t, []commandTest{
+		{
+			name: "GetSSTPEnabled",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				_, err := s.GetSSTPEnabled(ctx)
+				return err
+			},
+			want:  [][]string{{"SstpGet"}},
+			errno: 52, // ERR_NOT_ENOUGH_RIGHT
+		},
+		{
+			name: "SetSSTPEnabled",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetSSTPEnabled(ctx, true)
+			},
+			want:  [][]string{{"SstpEnable", "yes"}},
+			errno: 52, // ERR_NOT_ENOUGH_RIGHT
+		},
+		{
+			name: "SetSSTPEnabled disabled",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetSSTPEnabled(ctx, false)
+			},
+			want:  [][]string{{"SstpEnable", "no"}},
+			errno: 52, // ERR_NOT_ENOUGH_RIGHT
+		},
+	})
+}
+
+func TestGetSSTPEnabled(t *testing.T) {
+	tests := []struct {
+		name   string
+		stdout string
+		want   bool
+		parse  bool // Whether the output fails to parse
+	}{
+		{name: "fixture", want: true},
+		{name: "disabled", stdout: "Item,Value\nSSTP VPN Clone Server Enabled,No\n"},
+		{name: "missing label", stdout: "Item,Value\nEnable OpenVPN Clone Server Function,Yes\n", parse: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, runner := newServer()
+			if test.stdout != "" {
+				runner.Handle("SstpGet", softethertest.Response{Stdout: test.stdout})
+			}
+
+			enabled, err := s.GetSSTPEnabled(context.Background())
+			if test.parse {
+				checkKind(t, err, softether.KindParse)
+				if !errors.Is(err, softether.ErrParse) || enabled {
+					t.Errorf("enabled, err = %v, %v, want ErrParse", enabled, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if enabled != test.want {
+				t.Errorf("enabled = %v, want %v", enabled, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package softether
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+)
+
+// readCSV reads the records of the /CSV output of vpncmd. Lines which are not part
+// of the table, such as error messages, end up as records of a different length
+// and are dropped by the callers.
+func readCSV(cmd Command, output []byte) (records [][]string, err error) {
+	reader := csv.NewReader(bytes.NewReader(output))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	records, err = reader.ReadAll()
+	if err != nil {
+		return nil, parseFailure(cmd, "invalid CSV: %v", err)
+	}
+
+	for _, record := range records {
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+	}
+	return
+}
+
+// parseTable parses vpncmd /CSV output which lists one row per object, such as
+// SessionList, into one map per row keyed by the column headers. The header is the
+// first record starting with the column firstColumn, so that lines printed before
+// the table, such as prompts or warnings, are skipped. It fails if there is no header;
+// vpncmd prints it even if the table is empty.
+func parseTable(cmd Command, output []byte, firstColumn string) (rows []map[string]string, err error) {
+	records, err := readCSV(cmd, output)
+	if err != nil {
+		return
+	}
+
+	start := -1
+	for i, record := range records {
+		if record[0] == firstColumn {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil, parseFailure(cmd, "no %s table in output", cmd.Name)
+	}
+
+	header := records[start]
+	for _, record := range records[start+1:] {
+		if len(record) != len(header) {
+			continue // Not part of the table
+		}
+
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[column] = record[i]
+		}
+		rows = append(rows, row)
+	}
+
+	return
+}
+
+// parseKeyValue parses vpncmd /CSV output of the form "Item,Value", such as
+// ServerStatusGet, into a map. It fails if the output holds no such table.
+func parseKeyValue(cmd Command, output []byte) (values map[string]string, err error) {
+	records, err := readCSV(cmd, output)
+	if err != nil {
+		return
+	}
+
+	values = make(map[string]string)
+	for _, record := range records {
+		if len(record) != 2 || record[0] == SOFT_ETHER_TABLE_HEADER_KEY {
+			continue // Skip table header and lines which are not part of the table
+		}
+		values[record[0]] = record[1]
+	}
+
+	if len(values) == 0 {
+		return nil, parseFailure(cmd, "no %s table in output", cmd.Name)
+	}
+	return
+}
@@ -0,0 +1,105 @@
+package softether_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether/softethertest"
+)
+
+func TestParseTable(t *testing.T) {
+	tests := []struct {
+		name   string
+		stdout string
+		want   []string // Names of the users listed
+		parse  bool     // Whether the output fails to parse
+	}{
+		{
+			name: "table",
+			stdout: "User Name,Full Name,Description\n" +
+				"1,test@ecoworkinc.com,New Account\n" +
+				"2,other@ecoworkinc.com,\"Other Account, with a | pipe\"\n",
+			want: []string{"1", "2"},
+		},
+		{
+			name: "prompt before the table",
+			stdout: "Password: \n" +
+				"User Name,Full Name,Description\n" +
+				"1,test@ecoworkinc.com,New Account\n",
+			want: []string{"1"},
+		},
+		{
+			name: "warning before the table",
+			stdout: "Warning: the server certificate is not trusted, continuing\n\n" +
+				"User Name,Full Name,Description\n" +
+				"1,test@ecoworkinc.com,New Account\n",
+			want: []string{"1"},
+		},
+		{
+			name: "line after the table",
+			stdout: "User Name,Full Name,Description\n" +
+				"1,test@ecoworkinc.com,New Account\n" +
+				"The command completed successfully.\n",
+			want: []string{"1"},
+		},
+		{
+			name:   "empty table",
+			stdout: "User Name,Full Name,Description\n",
+		},
+		{
+			name:   "no table",
+			stdout: "Password: \n",
+			parse:  true,
+		},
+		{
+			name:   "no output",
+			stdout: "",
+			parse:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, runner := newServer()
+			runner.Handle("UserList", softethertest.Response{Stdout: test.stdout})
+
+			userList, err := s.GetUserList(context.Background())
+			if test.parse {
+				checkKind(t, err, softether.KindParse)
+				if !errors.Is(err, softether.ErrParse) || userList != nil {
+					t.Errorf("userList, err = %v, %v, want ErrParse", userList, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+
+			var names []string
+			for _, user := range userList {
+				names = append(names, user.Name)
+			}
+			if !reflect.DeepEqual(names, test.want) {
+				t.Errorf("users = %q, want %q", names, test.want)
+			}
+		})
+	}
+}
+
+func TestParseKeyValue(t *testing.T) {
+	s, runner := newServer()
+	runner.Handle("UserGet", softethertest.Response{
+		Stdout: "Password: \nItem,Value\nUser Name,1\nDescription,\"Note, with a comma\"\n",
+	})
+
+	user, err := s.GetUserInfo(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if user.Name != "1" || user.Description != "Note, with a comma" {
+		t.Errorf("Name, Description = %q, %q", user.Name, user.Description)
+	}
+}
@@ -0,0 +1,265 @@
+package softether
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Traffic holds the packet and byte counters SoftEther keeps for servers, sessions and users.
+type Traffic struct {
+	OutgoingUnicastPackets   int64
+	OutgoingUnicastBytes     int64
+	OutgoingBroadcastPackets int64
+	OutgoingBroadcastBytes   int64
+	IncomingUnicastPackets   int64
+	IncomingUnicastBytes     int64
+	IncomingBroadcastPackets int64
+	IncomingBroadcastBytes   int64
+}
+
+// IncomingBytes returns the total number of bytes received.
+func (t Traffic) IncomingBytes() int64 {
+	return t.IncomingUnicastBytes + t.IncomingBroadcastBytes
+}
+
+// OutgoingBytes returns the total number of bytes sent.
+func (t Traffic) OutgoingBytes() int64 {
+	return t.OutgoingUnicastBytes + t.OutgoingBroadcastBytes
+}
+
+// ServerStatus is the current status of a SoftEther server, as returned by GetServerStatus.
+type ServerStatus struct {
+	ServerType        string
+	NumberOfSockets   int
+	NumberOfHubs      int
+	NumberOfSessions  int
+	NumberOfMACTables int
+	NumberOfIPTables  int
+	NumberOfUsers     int
+	NumberOfGroups    int
+	ServerStartTime   time.Time
+	CurrentServerTime time.Time
+	Traffic
+}
+
+// Session is a VPN session connected to a Virtual Hub. GetSessionList only fills
+// in the fields shown by SessionList; GetSessionInfo fills in all of them.
+type Session struct {
+	Name               string
+	VLANID             int
+	Location           string
+	Username           string
+	ClientIP           string
+	ClientHostName     string
+	ClientPort         int
+	ClientProduct      string
+	ClientVersion      string
+	ClientOS           string
+	ServerHostName     string
+	ServerIP           string
+	ServerPort         int
+	Encryption         string
+	Compression        string
+	TCPConnections     int
+	MaxTCPConnections  int
+	Encrypted          bool
+	Compressed         bool
+	HalfDuplex         bool
+	BridgeMode         bool
+	MonitorMode        bool
+	UDPAcceleration    bool
+	TransferBytes      int64
+	TransferPackets    int64
+	OutgoingDataSize   int64
+	IncomingDataSize   int64
+	ConnectionStarted  time.Time
+	FirstEstablished   time.Time
+	CurrentEstablished time.Time
+	Traffic
+}
+
+// User is a user registered in a Virtual Hub. GetUserList only fills in the fields
+// shown by UserList; GetUserInfo fills in all of them.
+type User struct {
+	Name            string
+	FullName        string
+	Description     string
+	GroupName       string
+	AuthType        AuthType
+	CertCommonName  string // Signed certificate authentication: required common name; only filled in by GetUserInfo
+	CertSerial      string // Signed certificate authentication: required serial number in hex; only filled in by GetUserInfo
+	AuthAlias       string // RADIUS and NT domain authentication: name on the authentication server; only filled in by GetUserInfo
+	NumberOfLogins  int
+	LastLogin       time.Time // Zero if the user never logged in
+	ExpirationDate  time.Time // Zero if the user never expires
+	CreatedOn       time.Time
+	UpdatedOn       time.Time
+	TransferBytes   int64
+	TransferPackets int64
+	Traffic
+}
+
+var reWeekday = regexp.MustCompile(` \([A-Za-z]{3}\)`)
+
+// parseTime converts vpncmd timestamps such as "2017-04-19 (Wed) 02:05:16" or
+// "2017-04-20 10:11:12.345". vpncmd prints the server's local time without an
+// offset, so times are returned in UTC. Values like "(None)" and "No Expiration"
+// yield the zero time.
+func parseTime(value string) time.Time {
+	value = strings.TrimSpace(reWeekday.ReplaceAllString(value, ""))
+	t, err := time.Parse("2006-01-02 15:04:05.999", value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// parseCount converts vpncmd counters such as "4,734,874 bytes" to 4734874.
+func parseCount(value string) int64 {
+	count, _ := strconv.ParseInt(strings.Join(reFindIntegers.FindAllString(value, -1), ""), 10, 64)
+	return count
+}
+
+// parseInt converts plain vpncmd numbers. Values like "-" or "None" yield 0.
+func parseInt(value string) int {
+	i, _ := strconv.Atoi(strings.TrimSpace(value))
+	return i
+}
+
+// parseBool converts vpncmd flags such as "Yes", "Enabled (Algorithm: AES128-SHA)" or "No (No Compression)".
+func parseBool(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, "Yes") || strings.HasPrefix(value, "Enabled")
+}
+
+// formatBool converts flags to the "yes" and "no" vpncmd parameters expect.
+func formatBool(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+// parseString converts vpncmd strings, where "-" means empty.
+func parseString(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "-" {
+		return ""
+	}
+	return value
+}
+
+// parseTraffic reads the traffic counters out of a vpncmd key/value table.
+func parseTraffic(m map[string]string) Traffic {
+	return Traffic{
+		OutgoingUnicastPackets:   parseCount(m["Outgoing Unicast Packets"]),
+		OutgoingUnicastBytes:     parseCount(m["Outgoing Unicast Total Size"]),
+		OutgoingBroadcastPackets: parseCount(m["Outgoing Broadcast Packets"]),
+		OutgoingBroadcastBytes:   parseCount(m["Outgoing Broadcast Total Size"]),
+		IncomingUnicastPackets:   parseCount(m["Incoming Unicast Packets"]),
+		IncomingUnicastBytes:     parseCount(m["Incoming Unicast Total Size"]),
+		IncomingBroadcastPackets: parseCount(m["Incoming Broadcast Packets"]),
+		IncomingBroadcastBytes:   parseCount(m["Incoming Broadcast Total Size"]),
+	}
+}
+
+// parseServerStatus converts the output table of ServerStatusGet.
+func parseServerStatus(m map[string]string) ServerStatus {
+	return ServerStatus{
+		ServerType:        parseString(m["Server Type"]),
+		NumberOfSockets:   parseInt(m["Number of Active Sockets"]),
+		NumberOfHubs:      parseInt(m["Number of Virtual Hubs"]),
+		NumberOfSessions:  parseInt(m["Number of Sessions"]),
+		NumberOfMACTables: parseInt(m["Number of MAC Address Tables"]),
+		NumberOfIPTables:  parseInt(m["Number of IP Address Tables"]),
+		NumberOfUsers:     parseInt(m["Number of Users"]),
+		NumberOfGroups:    parseInt(m["Number of Groups"]),
+		ServerStartTime:   parseTime(m["Server Started at"]),
+		CurrentServerTime: parseTime(m["Current Time"]),
+		Traffic:           parseTraffic(m),
+	}
+}
+
+// parseSession converts a row of SessionList or the output table of SessionGet.
+func parseSession(m map[string]string) Session {
+	session := Session{
+		Name:               parseString(m["Session Name"]),
+		VLANID:             parseInt(m["VLAN ID"]),
+		Location:           parseString(m["Location"]),
+		Username:           parseString(m["User Name"]),
+		ClientIP:           parseString(m["Client IP Address"]),
+		ClientHostName:     parseString(m["Source Host Name"]),
+		ClientPort:         parseInt(m["Client Port (Reported)"]),
+		ClientProduct:      parseString(m["Client Product Name (Reported)"]),
+		ClientVersion:      parseString(m["Client Version (Reported)"]),
+		ClientOS:           parseString(m["Client OS Name (Reported)"]),
+		ServerHostName:     parseString(m["Server Host Name (Reported)"]),
+		ServerIP:           parseString(m["Server IP Address (Reported)"]),
+		ServerPort:         parseInt(m["Server Port (Reported)"]),
+		Encryption:         parseString(m["Encryption"]),
+		Compression:        parseString(m["Use of Compression"]),
+		TCPConnections:     parseInt(m["TCP Connections"]),
+		MaxTCPConnections:  parseInt(m["Maximum Number of TCP Connections"]),
+		Encrypted:          parseBool(m["Encryption"]),
+		Compressed:         parseBool(m["Use of Compression"]),
+		HalfDuplex:         parseBool(m["Half Duplex TCP Connection Mode"]),
+		BridgeMode:         parseBool(m["Bridge / Router Mode"]),
+		MonitorMode:        parseBool(m["Monitoring Mode"]),
+		UDPAcceleration:    parseBool(m["UDP Acceleration is Active"]),
+		TransferBytes:      parseCount(m["Transfer Bytes"]),
+		TransferPackets:    parseCount(m["Transfer Packets"]),
+		OutgoingDataSize:   parseCount(m["Outgoing Data Size"]),
+		IncomingDataSize:   parseCount(m["Incoming Data Size"]),
+		ConnectionStarted:  parseTime(m["Connection Started at"]),
+		FirstEstablished:   parseTime(m["First Session has been Established since"]),
+		CurrentEstablished: parseTime(m["Current Session has been Established since"]),
+		Traffic:            parseTraffic(m),
+	}
+
+	// SessionGet labels differ from the SessionList columns
+	if name, ok := m["User Name (Authentication)"]; ok {
+		session.Username = parseString(name)
+	}
+	if host, ok := m["Client Host Name"]; ok {
+		session.ClientHostName = parseString(host)
+	}
+	if connections, ok := m["Number of TCP Connections"]; ok {
+		session.TCPConnections = parseInt(connections)
+	}
+
+	return session
+}
+
+// parseUser converts a row of UserList or the output table of UserGet.
+func parseUser(m map[string]string) User {
+	user := User{
+		Name:            parseString(m["User Name"]),
+		FullName:        parseString(m["Full Name"]),
+		Description:     parseString(m["Description"]),
+		GroupName:       parseString(m["Group Name"]),
+		AuthType:        parseAuthType(m["Auth Method"]),
+		CertCommonName:  parseString(m["Limit of Certificate CN Value"]),
+		CertSerial:      parseString(m["Limit of Certificate Serial Number"]),
+		AuthAlias:       parseString(m["External Authentication Server Authentication User Name"]),
+		NumberOfLogins:  parseInt(m["Num Logins"]),
+		LastLogin:       parseTime(m["Last Login"]),
+		ExpirationDate:  parseTime(m["Expiration Date"]),
+		CreatedOn:       parseTime(m["Created on"]),
+		UpdatedOn:       parseTime(m["Updated on"]),
+		TransferBytes:   parseCount(m["Transfer Bytes"]),
+		TransferPackets: parseCount(m["Transfer Packets"]),
+		Traffic:         parseTraffic(m),
+	}
+
+	// UserGet labels differ from the UserList columns
+	if authType, ok := m["Auth Type"]; ok {
+		user.AuthType = parseAuthType(authType)
+	}
+	if logins, ok := m["Number of Logins"]; ok {
+		user.NumberOfLogins = parseInt(logins)
+	}
+
+	return user
+}
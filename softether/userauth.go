@@ -0,0 +1,150 @@
+package softether
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+)
+
+// AuthType is the way a User authenticates, as shown by vpncmd.
+type AuthType string
+
+// User authentication types.
+const (
+	AuthAnonymous  AuthType = "Anonymous Authentication"
+	AuthPassword   AuthType = "Password Authentication"
+	AuthUserCert   AuthType = "Individual Certificate Authentication"
+	AuthSignedCert AuthType = "Signed Certificate Authentication"
+	AuthRadius     AuthType = "RADIUS Authentication"
+	AuthNTDomain   AuthType = "NT Domain Authentication"
+)
+
+// parseAuthType converts the authentication type of a row of UserList or the output table of UserGet.
+func parseAuthType(value string) AuthType {
+	return AuthType(parseString(value))
+}
+
+// SetUserCertAuth executes vpncmd and makes a specific User in a specific Hub authenticate with
+// the X.509 certificate cert, in PEM or DER form. The certificate is handed to vpncmd through
+// a temporary file.
+func (s SoftEther) SetUserCertAuth(ctx context.Context, id string, cert []byte) (err error) {
+
+	// Validate the certificate, vpncmd would only fail with ERR_INTERNAL_ERROR
+	der := parsePEM(cert)
+	if _, parseErr := x509.ParseCertificate(der); parseErr != nil {
+		return invalidParameter("UserCertSet", parseErr)
+	}
+
+	certFile, err := writeTempFile("vpncmd-*.cer", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	if err != nil {
+		return NewKindError(KindExec, "UserCertSet", err)
+	}
+	defer os.Remove(certFile)
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd UserCertSet [NAME] /LOADCERT:[CERT_FILE]
+	cmd := s.hubCommand(
+		"UserCertSet",
+		id,
+		"/LOADCERT:"+certFile,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// GetUserCert executes vpncmd and gets the X.509 certificate a specific User in a specific Hub
+// authenticates with, as set by SetUserCertAuth. vpncmd hands the certificate over through a
+// temporary file.
+func (s SoftEther) GetUserCert(ctx context.Context, id string) (cert *x509.Certificate, err error) {
+	dir, err := tempDir("UserCertGet")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+	certFile := filepath.Join(dir, "user.cer")
+
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd UserCertGet [NAME] /SAVECERT:[CERT_FILE]
+	cmd := s.hubCommand(
+		"UserCertGet",
+		id,
+		"/SAVECERT:"+certFile,
+	)
+
+	// Execute
+	if _, err = s.execute(ctx, cmd); err != nil {
+		return
+	}
+
+	// Extract data
+	return readCertFile(cmd, certFile)
+}
+
+// SetUserSignedCertAuth executes vpncmd and makes a specific User in a specific Hub authenticate
+// with a certificate signed by one of the trusted CAs of the Hub. A non-empty commonName or
+// serial (in hex) restricts the certificates which are accepted.
+func (s SoftEther) SetUserSignedCertAuth(ctx context.Context, id, commonName, serial string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd UserSignedSet [NAME] /CN:[COMMON_NAME] /SERIAL:[SERIAL]
+	cmd := s.hubCommand(
+		"UserSignedSet",
+		id,
+		"/CN:"+commonName,
+		"/SERIAL:"+serial,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// SetUserRadiusAuth executes vpncmd and makes a specific User in a specific Hub authenticate
+// against the RADIUS server of the Hub, as alias or, if alias is empty, under its own name.
+func (s SoftEther) SetUserRadiusAuth(ctx context.Context, id, alias string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd UserRadiusSet [NAME] /ALIAS:[ALIAS]
+	cmd := s.hubCommand(
+		"UserRadiusSet",
+		id,
+		"/ALIAS:"+alias,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// SetUserNTLMAuth executes vpncmd and makes a specific User in a specific Hub authenticate
+// against the NT domain or Active Directory of the server, as alias or, if alias is empty,
+// under its own name.
+func (s SoftEther) SetUserNTLMAuth(ctx context.Context, id, alias string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd UserNTLMSet [NAME] /ALIAS:[ALIAS]
+	cmd := s.hubCommand(
+		"UserNTLMSet",
+		id,
+		"/ALIAS:"+alias,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
+
+// SetUserAnonymous executes vpncmd and lets a specific User in a specific Hub connect without authentication.
+func (s SoftEther) SetUserAnonymous(ctx context.Context, id string) (err error) {
+	// Command to execute
+	// vpncmd /server [IP]:[PORT] /hub:[HUB] /csv /cmd UserAnonymousSet [NAME]
+	cmd := s.hubCommand(
+		"UserAnonymousSet",
+		id,
+	)
+
+	// Execute
+	_, err = s.execute(ctx, cmd)
+	return
+}
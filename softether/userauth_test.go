@@ -0,0 +1,232 @@
+package softether_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/pem"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether"
+	"gitlab.ecoworkinc.com/subspace/softetherlib/softether/softethertest"
+)
+
+func TestUserAuthCommands(t *testing.T) {
+	runCommandTests(t, []commandTest{
+		{
+			name: "SetUserSignedCertAuth",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetUserSignedCertAuth(ctx, "1", "alice", "0A1B")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"UserSignedSet", "1", "/CN:alice", "/SERIAL:0A1B"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "SetUserSignedCertAuth any",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetUserSignedCertAuth(ctx, "1", "", "")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"UserSignedSet", "1", "/CN:", "/SERIAL:"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "SetUserRadiusAuth",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetUserRadiusAuth(ctx, "1", "alice@example.com")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"UserRadiusSet", "1", "/ALIAS:alice@example.com"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "SetUserNTLMAuth",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetUserNTLMAuth(ctx, "1", "")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"UserNTLMSet", "1", "/ALIAS:"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+		{
+			name: "SetUserAnonymous",
+			call: func(ctx context.Context, s softether.SoftEther) error {
+				return s.SetUserAnonymous(ctx, "1")
+			},
+			hub:   "subspace",
+			want:  [][]string{{"UserAnonymousSet", "1"}},
+			errno: 29, // ERR_OBJECT_NOT_FOUND
+		},
+	})
+}
+
+func TestSetUserCertAuth(t *testing.T) {
+	cert := newKeyPair(t, "alice")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+
+	// PEM and DER forms are both handed to vpncmd as PEM
+	for _, input := range [][]byte{certPEM, cert.Certificate[0]} {
+		runner := newLoadRunner()
+		s := softether.SoftEther{IP: "10.0.0.1", Password: "subspace", Hub: "subspace", Runner: runner}
+		if err := s.SetUserCertAuth(context.Background(), "1", input); err != nil {
+			t.Fatalf("err = %v", err)
+		}
+
+		commands := runner.Commands()
+		if len(commands) != 1 {
+			t.Fatalf("commands = %v, want UserCertSet", commands)
+		}
+		cmd := commands[0]
+		if cmd.Name != "UserCertSet" || cmd.Hub != "subspace" || len(cmd.Args) != 2 || cmd.Args[0] != "1" ||
+			!strings.HasPrefix(cmd.Args[1], "/LOADCERT:") {
+			t.Errorf("command = %s %q on Hub %q", cmd.Name, cmd.Args, cmd.Hub)
+		}
+
+		file := runner.files["/LOADCERT:"]
+		if !bytes.Equal(file.Data, certPEM) {
+			t.Errorf("loaded %q, want %q", file.Data, certPEM)
+		}
+		if file.Mode != 0600 {
+			t.Errorf("%s mode = %v, want 0600", file.Path, file.Mode)
+		}
+		if _, err := os.Stat(file.Path); !os.IsNotExist(err) {
+			t.Errorf("%s is left behind: %v", file.Path, err)
+		}
+	}
+
+	// An invalid certificate is not handed to vpncmd
+	s, runner := newServer()
+	checkError(t, s.SetUserCertAuth(context.Background(), "1", []byte("not a certificate")), 38)
+	if len(runner.Commands()) != 0 {
+		t.Errorf("commands = %v, want none", runner.Commands())
+	}
+
+	s, runner = newServer()
+	runner.Fail("UserCertSet", 29)
+	checkError(t, s.SetUserCertAuth(context.Background(), "1", certPEM), 29)
+}
+
+func TestGetUserCert(t *testing.T) {
+	s, runner := newServer()
+
+	cert, err := s.GetUserCert(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if cert.Subject.CommonName != "vpn.subspace.example" {
+		t.Errorf("CommonName = %q", cert.Subject.CommonName)
+	}
+
+	commands := runner.Commands()
+	if len(commands) != 1 {
+		t.Fatalf("commands = %v, want UserCertGet", commands)
+	}
+	cmd := commands[0]
+	if cmd.Name != "UserCertGet" || cmd.Hub != "subspace" || len(cmd.Args) != 2 || cmd.Args[0] != "1" ||
+		!strings.HasPrefix(cmd.Args[1], "/SAVECERT:") {
+		t.Fatalf("command = %s %q on Hub %q", cmd.Name, cmd.Args, cmd.Hub)
+	}
+	path := strings.TrimPrefix(cmd.Args[1], "/SAVECERT:")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("%s is left behind: %v", path, err)
+	}
+
+	// Failures
+	s, runner = newServer()
+	runner.Fail("UserCertGet", 29)
+	if cert, err := s.GetUserCert(context.Background(), "1"); cert != nil {
+		t.Errorf("cert = %v, want nil", cert)
+	} else {
+		checkError(t, err, 29)
+	}
+
+	for _, file := range []string{"", "not a certificate"} {
+		s, runner = newServer()
+		runner.Handle("UserCertGet", softethertest.Response{Stdout: "The command completed successfully.\n", File: file})
+		_, err := s.GetUserCert(context.Background(), "1")
+		checkKind(t, err, softether.KindParse)
+	}
+}
+
+func TestUserAuthType(t *testing.T) {
+	s, runner := newServer()
+	runner.Handle("UserList", softethertest.Response{
+		Stdout: "User Name,Full Name,Group Name,Description,Auth Method\n" +
+			"1,-,-,-,Anonymous Authentication\n" +
+			"2,-,-,-,Password Authentication\n" +
+			"3,-,-,-,Individual Certificate Authentication\n" +
+			"4,-,-,-,Signed Certificate Authentication\n" +
+			"5,-,-,-,RADIUS Authentication\n" +
+			"6,-,-,-,NT Domain Authentication\n",
+	})
+
+	userList, err := s.GetUserList(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []softether.AuthType{
+		softether.AuthAnonymous,
+		softether.AuthPassword,
+		softether.AuthUserCert,
+		softether.AuthSignedCert,
+		softether.AuthRadius,
+		softether.AuthNTDomain,
+	}
+	if len(userList) != len(want) {
+		t.Fatalf("userList = %+v, want %d users", userList, len(want))
+	}
+	for i, user := range userList {
+		if user.AuthType != want[i] {
+			t.Errorf("user %s AuthType = %q, want %q", user.Name, user.AuthType, want[i])
+		}
+	}
+
+	// UserGet labels the type differently
+	user, err := s.GetUserInfo(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if user.AuthType != softether.AuthPassword {
+		t.Errorf("AuthType = %q, want %q", user.AuthType, softether.AuthPassword)
+	}
+}
+
+func TestGetUserInfoAuthParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		stdout string
+		want   softether.User
+	}{
+		{
+			name: "signed certificate",
+			stdout: "Item,Value\nUser Name,1\nAuth Type,Signed Certificate Authentication\n" +
+				"Limit of Certificate CN Value,alice\nLimit of Certificate Serial Number,0A1B\n",
+			want: softether.User{Name: "1", AuthType: softether.AuthSignedCert, CertCommonName: "alice", CertSerial: "0A1B"},
+		},
+		{
+			name: "RADIUS",
+			stdout: "Item,Value\nUser Name,1\nAuth Type,RADIUS Authentication\n" +
+				"External Authentication Server Authentication User Name,alice@example.com\n",
+			want: softether.User{Name: "1", AuthType: softether.AuthRadius, AuthAlias: "alice@example.com"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, runner := newServer()
+			runner.Handle("UserGet", softethertest.Response{Stdout: test.stdout})
+
+			user, err := s.GetUserInfo(context.Background(), "1")
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if !reflect.DeepEqual(user, test.want) {
+				t.Errorf("user = %+v, want %+v", user, test.want)
+			}
+		})
+	}
+}